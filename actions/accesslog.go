@@ -0,0 +1,325 @@
+package actions
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+
+	// access log formats
+	formatText     = "text"
+	formatJSON     = "json"
+	formatLogfmt   = "logfmt"
+	formatCombined = "combined"
+
+	// access log destinations other than files
+	destinationStdout = "stdout"
+	destinationStderr = "stderr"
+	destinationSyslog = "syslog"
+)
+
+// logLevel orders the severity of log entries
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l logLevel) String() string {
+	return levelNames[l]
+}
+
+// parseLevel parses the name of a log level
+func parseLevel(s string) (logLevel, error) {
+	for i, name := range levelNames {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			return logLevel(i), nil
+		}
+	}
+	return levelInfo, errors.Errorf("unsupported log level %q, expected one of %s", s, strings.Join(levelNames, ", "))
+}
+
+var (
+	accessLogFormat = formatText
+	accessLogDest   string
+	logLevelName    = levelInfo.String()
+
+	// accessLogger records the requests served, writing to the standard
+	// logger until the service is configured
+	accessLogger = &accessLog{format: formatText, level: levelInfo}
+
+	// requestIDPattern bounds the request ids accepted from clients, which
+	// are otherwise replaced, so that they may be logged verbatim
+	requestIDPattern = regexp.MustCompile(`^[\w.:/+=@-]{1,128}$`)
+)
+
+// logSink writes formatted access log entries
+type logSink interface {
+	write(level logLevel, line []byte) error
+	Close() error
+}
+
+// writerSink writes entries to a stream, serializing concurrent writes so
+// that lines are not interleaved
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *writerSink) write(_ logLevel, line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(line)
+	return err
+}
+
+func (s *writerSink) Close() error {
+	if c, ok := s.w.(io.Closer); ok && s.w != os.Stdout && s.w != os.Stderr {
+		return c.Close()
+	}
+	return nil
+}
+
+// openSink opens the destination of the access log, which is stdout, stderr,
+// syslog, syslog://host:port or the path of a file to which entries are
+// appended
+func openSink(dest string) (logSink, error) {
+	switch {
+	case dest == destinationStdout:
+		return &writerSink{w: os.Stdout}, nil
+	case dest == "" || dest == destinationStderr:
+		return &writerSink{w: os.Stderr}, nil
+	case dest == destinationSyslog || strings.HasPrefix(dest, destinationSyslog+"://"):
+		return openSyslog(strings.TrimPrefix(strings.TrimPrefix(dest, destinationSyslog), "://"))
+	}
+
+	f, err := os.OpenFile(dest, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0640)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open access log")
+	}
+	return &writerSink{w: f}, nil
+}
+
+// accessLog formats and writes the entries of requests at or above its level
+type accessLog struct {
+	format string
+	level  logLevel
+	// sink receives the entries, which are written to the standard logger
+	// when it is nil
+	sink logSink
+}
+
+// newAccessLog initializes an access log of the format, level and destination
+func newAccessLog(format, level, dest string) (*accessLog, error) {
+	switch format {
+	case formatText, formatJSON, formatLogfmt, formatCombined:
+	default:
+		return nil, errors.Errorf("unsupported access log format %q, expected text, json, logfmt or combined", format)
+	}
+	lvl, err := parseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &accessLog{format: format, level: lvl}
+	// text entries keep the timestamps of the standard logger when no
+	// destination is given
+	if dest != "" || format != formatText {
+		if l.sink, err = openSink(dest); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Close closes the destination of the access log
+func (l *accessLog) Close() error {
+	if l.sink == nil {
+		return nil
+	}
+	return l.sink.Close()
+}
+
+// entry describes a request served
+type entry struct {
+	Time      time.Time
+	Level     logLevel
+	RequestID string
+	Remote    string
+	Method    string
+	URI       string
+	Proto     string
+	Status    int
+	Bytes     int64
+	Duration  time.Duration
+	UserAgent string
+	Referer   string
+}
+
+// levelOf classifies the request by its outcome, demoting successful probes
+// and metrics scrapes so they may be filtered out
+func levelOf(path string, status int) logLevel {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return levelError
+	case status >= http.StatusBadRequest:
+		return levelWarn
+	case unguarded[path]:
+		return levelDebug
+	default:
+		return levelInfo
+	}
+}
+
+// record writes the entry when its level is enabled
+func (l *accessLog) record(e *entry) {
+	if e.Level < l.level {
+		return
+	}
+	if l.sink == nil {
+		log.Print(string(l.encode(e)))
+		return
+	}
+	if err := l.sink.write(e.Level, l.encode(e)); err != nil {
+		log.Println("failed to write access log: ", err)
+	}
+}
+
+// encode formats the entry as a single line
+func (l *accessLog) encode(e *entry) []byte {
+	buf := new(bytes.Buffer)
+	ms := float64(e.Duration) / float64(time.Millisecond)
+	switch l.format {
+	case formatJSON:
+		json.NewEncoder(buf).Encode(struct {
+			Time      string  `json:"time"`
+			Level     string  `json:"level"`
+			RequestID string  `json:"request_id"`
+			Remote    string  `json:"remote"`
+			Method    string  `json:"method"`
+			URI       string  `json:"uri"`
+			Proto     string  `json:"proto"`
+			Status    int     `json:"status"`
+			Bytes     int64   `json:"bytes"`
+			Duration  float64 `json:"duration_ms"`
+			UserAgent string  `json:"user_agent,omitempty"`
+			Referer   string  `json:"referer,omitempty"`
+		}{
+			e.Time.Format(time.RFC3339Nano), e.Level.String(), e.RequestID, e.Remote, e.Method, e.URI,
+			e.Proto, e.Status, e.Bytes, ms, e.UserAgent, e.Referer,
+		})
+		return buf.Bytes()
+	case formatLogfmt:
+		pairs := []string{
+			"time", e.Time.Format(time.RFC3339Nano), "level", e.Level.String(), "request_id", e.RequestID,
+			"remote", e.Remote, "method", e.Method, "uri", e.URI, "proto", e.Proto,
+			"status", strconv.Itoa(e.Status), "bytes", strconv.FormatInt(e.Bytes, 10),
+			"duration_ms", strconv.FormatFloat(ms, 'f', 3, 64), "user_agent", e.UserAgent, "referer", e.Referer,
+		}
+		for i := 0; i < len(pairs); i += 2 {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			fmt.Fprintf(buf, "%s=%s", pairs[i], logfmtValue(pairs[i+1]))
+		}
+	case formatCombined:
+		// the apache combined format, extended with the request id and the
+		// duration in microseconds
+		fmt.Fprintf(buf, "%s - - [%s] %q %d %s %q %q %s %d",
+			e.Remote, e.Time.Format("02/Jan/2006:15:04:05 -0700"), e.Method+" "+e.URI+" "+e.Proto,
+			e.Status, combinedBytes(e.Bytes), dash(e.Referer), dash(e.UserAgent), e.RequestID,
+			e.Duration/time.Microsecond)
+	default:
+		fmt.Fprintf(buf, "%s %s %s %s %d %d %s %s",
+			e.Remote, e.Method, e.URI, e.Proto, e.Status, e.Bytes, e.Duration, e.RequestID)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// logfmtValue quotes values that are empty or contain spaces, quotes or
+// equals signs
+func logfmtValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \"=\\") || strconv.Quote(s) != `"`+s+`"` {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func combinedBytes(n int64) string {
+	if n == 0 {
+		return "-"
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// requestID returns the id of the request, which is taken from the client
+// when it is well formed and is otherwise generated
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(requestIDHeader); requestIDPattern.MatchString(id) {
+		return id
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// logger is logging middleware, which assigns each request an id, echoed to
+// the client and passed on to the handler, and records it in the access log
+func logger(next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := requestID(r)
+		r.Header.Set(requestIDHeader, id)
+		w.Header().Set(requestIDHeader, id)
+
+		wi := &interceptor{delegate: w}
+		start := time.Now()
+		defer func() {
+			accessLogger.record(&entry{
+				Time:      start,
+				Level:     levelOf(r.URL.Path, wi.StatusCode()),
+				RequestID: id,
+				Remote:    clientIP(r, proxies),
+				Method:    r.Method,
+				URI:       r.RequestURI,
+				Proto:     r.Proto,
+				Status:    wi.StatusCode(),
+				Bytes:     wi.Bytes,
+				Duration:  time.Since(start),
+				UserAgent: r.UserAgent(),
+				Referer:   r.Referer(),
+			})
+		}()
+		next.ServeHTTP(wi, r)
+	})
+}
@@ -0,0 +1,10 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package actions
+
+import "github.com/pkg/errors"
+
+func openSyslog(string) (logSink, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,47 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package actions
+
+import (
+	"log/syslog"
+
+	"github.com/pkg/errors"
+)
+
+// syslogSink writes entries to syslog at the priority of their level
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+// openSyslog connects to the local syslog daemon or, when addr is given, to
+// the daemon listening on udp at addr
+func openSyslog(addr string) (logSink, error) {
+	network := ""
+	if addr != "" {
+		network = "udp"
+	}
+	w, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_DAEMON, "mac2vendor")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to syslog")
+	}
+	return &syslogSink{w: w}, nil
+}
+
+func (s *syslogSink) write(level logLevel, line []byte) error {
+	msg := string(line)
+	switch level {
+	case levelDebug:
+		return s.w.Debug(msg)
+	case levelWarn:
+		return s.w.Warning(msg)
+	case levelError:
+		return s.w.Err(msg)
+	default:
+		return s.w.Info(msg)
+	}
+}
+
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,117 @@
+package actions
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessLogFormats(t *testing.T) {
+	e := &entry{
+		Time:      time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC),
+		Level:     levelWarn,
+		RequestID: "abc123",
+		Remote:    "192.0.2.1",
+		Method:    http.MethodGet,
+		URI:       "/v1/lookup/not-a-mac",
+		Proto:     "HTTP/1.1",
+		Status:    http.StatusBadRequest,
+		Bytes:     142,
+		Duration:  1500 * time.Microsecond,
+		UserAgent: "curl/7.64.0",
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{formatText, "192.0.2.1 GET /v1/lookup/not-a-mac HTTP/1.1 400 142 1.5ms abc123\n"},
+		{formatLogfmt, `time=2019-03-04T05:06:07Z level=warn request_id=abc123 remote=192.0.2.1 method=GET uri=/v1/lookup/not-a-mac proto=HTTP/1.1 status=400 bytes=142 duration_ms=1.500 user_agent=curl/7.64.0 referer=""` + "\n"},
+		{formatCombined, `192.0.2.1 - - [04/Mar/2019:05:06:07 +0000] "GET /v1/lookup/not-a-mac HTTP/1.1" 400 142 "-" "curl/7.64.0" abc123 1500` + "\n"},
+		{formatJSON, `{"time":"2019-03-04T05:06:07Z","level":"warn","request_id":"abc123","remote":"192.0.2.1","method":"GET","uri":"/v1/lookup/not-a-mac","proto":"HTTP/1.1","status":400,"bytes":142,"duration_ms":1.5,"user_agent":"curl/7.64.0"}` + "\n"},
+	}
+	for _, tt := range tests {
+		l := &accessLog{format: tt.format}
+		if line := string(l.encode(e)); line != tt.expected {
+			t.Errorf("%s: received %s; expected %s", tt.format, line, tt.expected)
+		}
+	}
+
+	if _, err := newAccessLog("xml", "info", ""); err == nil {
+		t.Error("expected an unsupported format to be rejected")
+	}
+	if _, err := newAccessLog(formatJSON, "verbose", ""); err == nil {
+		t.Error("expected an unsupported level to be rejected")
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mac2vnd")
+	if err != nil {
+		t.Fatal("failed to create directory: ", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "access.log")
+	l, err := newAccessLog(formatJSON, "info", name)
+	if err != nil {
+		t.Fatal("failed to open access log: ", err)
+	}
+	defer func(prev *accessLog) { accessLogger = prev }(accessLogger)
+	accessLogger = l
+
+	handler := logger(newRouter().ServeHTTP)
+	serve := func(path, id string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if id != "" {
+			r.Header.Set(requestIDHeader, id)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := serve("/v1/lookup/84:38:35:77:aa:52", "client-id"); w.Header().Get(requestIDHeader) != "client-id" {
+		t.Errorf("expected the request id to be echoed, received %q", w.Header().Get(requestIDHeader))
+	}
+	w := serve("/v1/lookup/not-a-mac", "not a valid id")
+	generated := w.Header().Get(requestIDHeader)
+	if generated == "" || generated == "not a valid id" {
+		t.Errorf("expected a request id to be generated, received %q", generated)
+	}
+	serve(healthPath, "")
+	l.Close()
+
+	b, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal("failed to read access log: ", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected probes to be filtered at info, logged %d entries:\n%s", len(lines), b)
+	}
+
+	var entries []map[string]interface{}
+	for _, line := range lines {
+		var e map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("failed to decode %s: %v", line, err)
+		}
+		entries = append(entries, e)
+	}
+	if entries[0]["request_id"] != "client-id" || entries[0]["level"] != "info" || entries[0]["status"] != float64(http.StatusOK) {
+		t.Errorf("logged unexpected entry: %v", entries[0])
+	}
+	if entries[1]["request_id"] != generated || entries[1]["level"] != "warn" || entries[1]["bytes"] == float64(0) {
+		t.Errorf("logged unexpected entry: %v", entries[1])
+	}
+	if _, ok := entries[1]["duration_ms"]; !ok {
+		t.Errorf("expected the duration to be logged: %v", entries[1])
+	}
+}
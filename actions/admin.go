@@ -0,0 +1,385 @@
+package actions
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+)
+
+const (
+	adminPrefix   = "/admin/"
+	overridesPath = adminPrefix + "overrides"
+
+	contentTypeText = "text/plain"
+
+	codeNotConfigured   = "not_configured"
+	codeInvalidOverride = "invalid_override"
+	codeUpdateFailed    = "update_failed"
+
+	// maxOverridesBytes bounds the size of uploaded overrides
+	maxOverridesBytes = 4 << 20
+)
+
+var (
+	adminKeysFile string
+	databaseFile  string
+	overridesFile string
+	auditLogDest  string
+
+	// adminKeys are the digests of the keys accepted by the admin api, which
+	// is disabled when nil
+	adminKeys map[[sha256.Size]byte]bool
+
+	// overrides take precedence over the database in the lookups served
+	overrides atomic.Pointer[m2v.Overrides]
+
+	// adminMu serializes administrative changes along with their persistence
+	adminMu sync.Mutex
+
+	// auditLog receives a record of each administrative change, written to
+	// the standard logger when nil
+	auditLog logSink
+)
+
+func init() {
+	overrides.Store(m2v.NewOverrides())
+}
+
+// vendorOf resolves the address to the vendor assigned by an override, or
+// otherwise to the vendor registered in the default database
+func vendorOf(hw net.HardwareAddr) (string, error) {
+	if e, ok := overrides.Load().Find(hw); ok {
+		return e.Vendor, nil
+	}
+	return m2v.Lookup(hw)
+}
+
+// loadOverrides reads the overrides file, which is created on the first
+// change when it does not yet exist
+func loadOverrides(name string) (*m2v.Overrides, error) {
+	if _, err := os.Stat(name); os.IsNotExist(err) {
+		return m2v.NewOverrides(), nil
+	}
+	return m2v.LoadOverrides(name)
+}
+
+// Override is the v1 resource model of a locally assigned vendor
+type Override struct {
+	Key    string `json:"key"`
+	Vendor string `json:"vendor"`
+}
+
+// Reload is the v1 resource model of the outcome of a reload
+type Reload struct {
+	Database  *Update `json:"database,omitempty"`
+	Overrides int     `json:"overrides"`
+}
+
+// auditEntry records an administrative change
+type auditEntry struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"request_id"`
+	Remote    string    `json:"remote"`
+	Key       string    `json:"key"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+type auditContextKey struct{}
+
+// adminHandler serves the admin api when admin keys are configured
+func adminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(adminPrefix+"reload", adminReload)
+	mux.HandleFunc(adminPrefix+"update", adminUpdate)
+	mux.HandleFunc(adminPrefix+"updates", adminUpdates)
+	mux.HandleFunc(overridesPath, adminOverrides)
+	mux.HandleFunc(overridesPath+"/", adminOverride)
+	mux.HandleFunc(adminPrefix, notFoundV1)
+	return audited(mux)
+}
+
+// audited records the outcome of each change made through the admin api,
+// identifying the admin by a digest of their key
+func audited(next http.Handler) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if adminKeys == nil {
+			notFoundV1(w, r)
+			return
+		}
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		entry := &auditEntry{
+			Time:      time.Now().UTC(),
+			RequestID: r.Header.Get(requestIDHeader),
+			Remote:    clientIP(r, proxies),
+			Key:       fmt.Sprintf("%x", sha256.Sum256([]byte(credential(r))))[:12],
+			Method:    r.Method,
+			Path:      r.URL.Path,
+		}
+		wi := &interceptor{delegate: w}
+		next.ServeHTTP(wi, r.WithContext(context.WithValue(r.Context(), auditContextKey{}, entry)))
+		entry.Status = wi.StatusCode()
+
+		line, _ := json.Marshal(entry)
+		if auditLog == nil {
+			log.Println("audit:", string(line))
+		} else if err := auditLog.write(levelInfo, append(line, '\n')); err != nil {
+			log.Println("failed to write audit log: ", err)
+		}
+	})
+}
+
+// auditf describes the change made by the request in its audit record
+func auditf(r *http.Request, format string, args ...interface{}) {
+	if entry, ok := r.Context().Value(auditContextKey{}).(*auditEntry); ok {
+		entry.Detail = fmt.Sprintf(format, args...)
+	}
+}
+
+// allowMethods responds with 405 unless the request uses one of the methods
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeProblem(w, newProblem(r, http.StatusMethodNotAllowed, codeMethodNotAllowed,
+		"the resource only supports "+strings.Join(methods, ", ")))
+	return false
+}
+
+// adminReload reloads the database and overrides from their files
+func adminReload(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+	if databaseFile == "" && overridesFile == "" {
+		writeProblem(w, newProblem(r, http.StatusConflict, codeNotConfigured, "neither a database nor an overrides file is configured"))
+		return
+	}
+
+	adminMu.Lock()
+	defer adminMu.Unlock()
+	swapMu.Lock()
+	defer swapMu.Unlock()
+
+	var db *m2v.Database
+	o := overrides.Load()
+	var err error
+	if databaseFile != "" {
+		if db, err = m2v.Open(databaseFile); err != nil {
+			writeProblem(w, newProblem(r, http.StatusInternalServerError, codeInternal, err.Error()))
+			return
+		}
+	}
+	if overridesFile != "" {
+		if o, err = loadOverrides(overridesFile); err != nil {
+			writeProblem(w, newProblem(r, http.StatusInternalServerError, codeInternal, err.Error()))
+			return
+		}
+	}
+
+	res := &Reload{Overrides: len(o.Entries())}
+	if db != nil {
+		res.Database = swapDatabase(db, reasonReload)
+	}
+	overrides.Store(o)
+	auditf(r, "reloaded database version %s and %d overrides", m2v.Version(), res.Overrides)
+	writeJSON(w, http.StatusOK, res)
+}
+
+// adminUpdate downloads the latest listing and replaces the database with it
+func adminUpdate(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	// updates are reported by the background refresher when it is enabled
+	f := refreshes
+	if f == nil {
+		f = newRefresher(source, refreshInterval)
+	}
+	update, err := f.refresh(r.Context(), reasonUpdate)
+	if err != nil {
+		auditf(r, "update failed: %v", err)
+		writeProblem(w, newProblem(r, http.StatusBadGateway, codeUpdateFailed, err.Error()))
+		return
+	}
+	auditf(r, "updated database to version %s with %d added, %d removed and %d changed",
+		update.Version, update.Added, update.Removed, update.Changed)
+	writeJSON(w, http.StatusOK, update)
+}
+
+// adminUpdates lists the recent replacements of the database
+func adminUpdates(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+	writeJSON(w, http.StatusOK, updates())
+}
+
+// adminOverrides lists the overrides, or replaces them with a json array of
+// overrides or an overrides file
+func adminOverrides(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodHead, http.MethodPut) {
+		return
+	}
+	if r.Method != http.MethodPut {
+		writeJSON(w, http.StatusOK, listOverrides(overrides.Load()))
+		return
+	}
+
+	mediaType := contentTypeJSON
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, _ = mime.ParseMediaType(ct)
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxOverridesBytes)
+	var o *m2v.Overrides
+	var err error
+	switch mediaType {
+	case contentTypeJSON:
+		var entries []Override
+		if err = json.NewDecoder(body).Decode(&entries); err == nil {
+			o = m2v.NewOverrides()
+			for _, e := range entries {
+				if err = o.Set(e.Key, e.Vendor); err != nil {
+					break
+				}
+			}
+		}
+	case contentTypeText:
+		o, err = m2v.ReadOverrides(body)
+	default:
+		writeProblem(w, newProblem(r, http.StatusUnsupportedMediaType, codeUnsupported,
+			fmt.Sprintf("overrides must be sent as %s or %s", contentTypeJSON, contentTypeText)))
+		return
+	}
+	if err != nil {
+		writeProblem(w, newProblem(r, http.StatusBadRequest, codeInvalidOverride, err.Error()))
+		return
+	}
+
+	adminMu.Lock()
+	defer adminMu.Unlock()
+	if err := storeOverrides(o); err != nil {
+		writeProblem(w, newProblem(r, http.StatusInternalServerError, codeInternal, err.Error()))
+		return
+	}
+	auditf(r, "replaced overrides with %d entries", len(o.Entries()))
+	writeJSON(w, http.StatusOK, listOverrides(o))
+}
+
+// adminOverride reads, assigns or deletes the override of a single address
+// or prefix
+func adminOverride(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete) {
+		return
+	}
+
+	hw, err := m2v.ParsePrefix(strings.TrimPrefix(r.URL.Path, overridesPath+"/"))
+	if err != nil {
+		writeProblem(w, newProblem(r, http.StatusBadRequest, codeInvalidOverride, err.Error()))
+		return
+	}
+	key := hw.String()
+
+	adminMu.Lock()
+	defer adminMu.Unlock()
+	current := overrides.Load()
+	vendor, found := "", false
+	for _, e := range current.Entries() {
+		if e.Prefix == key {
+			vendor, found = e.Vendor, true
+		}
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req struct {
+			Vendor string `json:"vendor"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxOverridesBytes)).Decode(&req); err != nil {
+			writeProblem(w, newProblem(r, http.StatusBadRequest, codeInvalidBody, err.Error()))
+			return
+		}
+		o := cloneOverrides(current)
+		if err := o.Set(key, strings.TrimSpace(req.Vendor)); err != nil {
+			writeProblem(w, newProblem(r, http.StatusBadRequest, codeInvalidOverride, err.Error()))
+			return
+		}
+		if err := storeOverrides(o); err != nil {
+			writeProblem(w, newProblem(r, http.StatusInternalServerError, codeInternal, err.Error()))
+			return
+		}
+		auditf(r, "assigned %s to %q, previously %q", key, strings.TrimSpace(req.Vendor), vendor)
+		writeJSON(w, http.StatusOK, &Override{Key: key, Vendor: strings.TrimSpace(req.Vendor)})
+	case http.MethodDelete:
+		if !found {
+			notFoundV1(w, r)
+			return
+		}
+		o := cloneOverrides(current)
+		o.Delete(key)
+		if err := storeOverrides(o); err != nil {
+			writeProblem(w, newProblem(r, http.StatusInternalServerError, codeInternal, err.Error()))
+			return
+		}
+		auditf(r, "deleted %s, previously %q", key, vendor)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		if !found {
+			notFoundV1(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, &Override{Key: key, Vendor: vendor})
+	}
+}
+
+// storeOverrides persists the overrides, when an overrides file is
+// configured, before serving them. The caller must hold adminMu
+func storeOverrides(o *m2v.Overrides) error {
+	if overridesFile != "" {
+		if err := o.Save(overridesFile); err != nil {
+			return err
+		}
+	}
+	overrides.Store(o)
+	return nil
+}
+
+func cloneOverrides(o *m2v.Overrides) *m2v.Overrides {
+	clone := m2v.NewOverrides()
+	for _, e := range o.Entries() {
+		clone.Set(e.Prefix, e.Vendor)
+	}
+	return clone
+}
+
+func listOverrides(o *m2v.Overrides) []Override {
+	entries := o.Entries()
+	list := make([]Override, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, Override{Key: e.Prefix, Vendor: e.Vendor})
+	}
+	return list
+}
@@ -0,0 +1,159 @@
+package actions
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	m2v "github.com/n3integration/mac2vendor"
+)
+
+func TestAdmin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mac2vnd")
+	if err != nil {
+		t.Fatal("failed to create directory: ", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the database served is a copy of the embedded one without a vendor
+	var listing strings.Builder
+	for _, e := range m2v.Embedded().Entries() {
+		if e.Prefix != "00:00:0c" {
+			fmt.Fprintf(&listing, "%s\t%s\n", e.Prefix, e.Vendor)
+		}
+	}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(listing.String()))
+	}))
+	defer upstream.Close()
+
+	audit := new(bytes.Buffer)
+	defer func(keys map[[sha256.Size]byte]bool, db, o, src string, sink logSink, f *refresher) {
+		adminKeys, databaseFile, overridesFile, source, auditLog, refreshes = keys, db, o, src, sink, f
+		overrides.Store(m2v.NewOverrides())
+		m2v.SetDefault(m2v.Embedded())
+	}(adminKeys, databaseFile, overridesFile, source, auditLog, refreshes)
+	adminKeys = map[[sha256.Size]byte]bool{sha256.Sum256([]byte("admin")): true}
+	databaseFile = filepath.Join(dir, "oui.tsv")
+	overridesFile = filepath.Join(dir, "overrides")
+	source = upstream.URL
+	auditLog = &writerSink{w: audit}
+	refreshes = nil
+
+	handler := guard(newRouter())
+	request := func(method, path, contentType, body string, key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		if key != "" {
+			r.Header.Set(apiKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	vendor := func(mac string) string {
+		var addr Address
+		json.Unmarshal(request(http.MethodGet, "/v1/lookup/"+mac, "", "", "").Body.Bytes(), &addr)
+		if addr.Vendor == nil {
+			return ""
+		}
+		return addr.Vendor.Name
+	}
+
+	if w := request(http.MethodGet, adminPrefix+"updates", "", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("expected the admin api to require a key, received %v", w.Code)
+	}
+	if w := request(http.MethodPost, adminPrefix+"reload", "", "", "admin"); w.Code != http.StatusInternalServerError {
+		t.Errorf("expected a missing database to fail to reload, received %v %s", w.Code, w.Body)
+	}
+
+	// updates are downloaded, served, persisted and recorded
+	w := request(http.MethodPost, adminPrefix+"update", "", "", "admin")
+	var update Update
+	if err := json.Unmarshal(w.Body.Bytes(), &update); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("failed to update: %v %s", w.Code, w.Body)
+	}
+	if update.Reason != reasonUpdate || update.Removed != 1 || update.Changes[0].Prefix != "00:00:0c" {
+		t.Errorf("recorded unexpected update: %+v", update)
+	}
+	if vendor("00:00:0c:00:00:01") != "" {
+		t.Error("expected the updated database to be served")
+	}
+	if db, err := m2v.Open(databaseFile); err != nil || db.Version() != update.Version {
+		t.Errorf("expected the update to be persisted: %v", err)
+	}
+
+	// overrides are assigned, replaced and deleted, and persisted
+	if w := request(http.MethodPut, overridesPath+"/84-38-35-77-AA-52", contentTypeJSON, `{"vendor": "Build server"}`, "admin"); w.Code != http.StatusOK {
+		t.Errorf("failed to assign override: %v %s", w.Code, w.Body)
+	}
+	if vendor("84:38:35:77:aa:52") != "Build server" || vendor("84:38:35:77:aa:53") != "Apple, Inc." {
+		t.Error("expected the override to take precedence over the database")
+	}
+	if w := request(http.MethodPut, overridesPath+"/843835", contentTypeJSON, `{"vendor": ""}`, "admin"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected an override without a vendor to be rejected, received %v", w.Code)
+	}
+	if w := request(http.MethodPut, overridesPath, contentTypeText, "# lab\n843835 Apple lab\n52:54:00:00:00:01 Router\n", "admin"); w.Code != http.StatusOK {
+		t.Errorf("failed to replace overrides: %v %s", w.Code, w.Body)
+	}
+	if w := request(http.MethodDelete, overridesPath+"/52:54:00:00:00:01", "", "", "admin"); w.Code != http.StatusNoContent {
+		t.Errorf("failed to delete override: %v", w.Code)
+	}
+	if w := request(http.MethodDelete, overridesPath+"/52:54:00:00:00:01", "", "", "admin"); w.Code != http.StatusNotFound {
+		t.Errorf("expected a missing override to be reported, received %v", w.Code)
+	}
+	var list []Override
+	json.Unmarshal(request(http.MethodGet, overridesPath, "", "", "admin").Body.Bytes(), &list)
+	if len(list) != 1 || list[0] != (Override{Key: "84:38:35", Vendor: "Apple lab"}) {
+		t.Errorf("listed unexpected overrides: %+v", list)
+	}
+	if saved, err := m2v.LoadOverrides(overridesFile); err != nil || len(saved.Entries()) != 1 {
+		t.Errorf("expected the overrides to be persisted: %v", err)
+	}
+
+	// reloads restore the persisted state after changes made elsewhere
+	overrides.Store(m2v.NewOverrides())
+	m2v.SetDefault(m2v.Embedded())
+	w = request(http.MethodPost, adminPrefix+"reload", "", "", "admin")
+	var reload Reload
+	if err := json.Unmarshal(w.Body.Bytes(), &reload); err != nil || reload.Overrides != 1 || reload.Database == nil || reload.Database.Removed != 1 {
+		t.Errorf("received unexpected reload: %v %s", w.Code, w.Body)
+	}
+	if vendor("84:38:35:00:00:01") != "Apple lab" || vendor("00:00:0c:00:00:01") != "" {
+		t.Error("expected the persisted database and overrides to be served")
+	}
+
+	var history []Update
+	json.Unmarshal(request(http.MethodGet, adminPrefix+"updates", "", "", "admin").Body.Bytes(), &history)
+	if len(history) < 2 || history[0].Reason != reasonReload || history[1].Reason != reasonUpdate {
+		t.Errorf("listed unexpected updates: %+v", history)
+	}
+
+	// every change is audited, but reads are not
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	if len(lines) != 8 {
+		t.Fatalf("audited %d changes; expected 8:\n%s", len(lines), audit)
+	}
+	var entry auditEntry
+	if err := json.Unmarshal([]byte(lines[2]), &entry); err != nil {
+		t.Fatal("failed to decode audit entry: ", err)
+	}
+	if entry.Method != http.MethodPut || entry.Status != http.StatusOK || entry.Key == "" || !strings.Contains(entry.Detail, "Build server") {
+		t.Errorf("audited unexpected entry: %+v", entry)
+	}
+
+	adminKeys = nil
+	if w := request(http.MethodGet, adminPrefix+"updates", "", "", "admin"); w.Code != http.StatusNotFound {
+		t.Errorf("expected the admin api to be disabled without keys, received %v", w.Code)
+	}
+}
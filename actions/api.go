@@ -0,0 +1,173 @@
+package actions
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+
+	m2v "github.com/n3integration/mac2vendor"
+)
+
+const (
+	apiPrefix = "/v1/"
+
+	contentTypeJSON    = "application/json"
+	contentTypeProblem = "application/problem+json"
+
+	codeInvalidMAC       = "invalid_mac"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeNotFound         = "not_found"
+	codeInternal         = "internal_error"
+)
+
+// Address is the v1 resource model of a resolved mac address
+type Address struct {
+	MAC        string  `json:"mac"`
+	Normalized string  `json:"normalized"`
+	Prefix     string  `json:"prefix"`
+	Vendor     *Vendor `json:"vendor"`
+	Flags      Flags   `json:"flags"`
+}
+
+// Vendor is the organization registered for an address prefix
+type Vendor struct {
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+}
+
+// Flags describes the properties encoded in an address
+type Flags struct {
+	Multicast  bool   `json:"multicast"`
+	Broadcast  bool   `json:"broadcast"`
+	Local      bool   `json:"local"`
+	Virtual    bool   `json:"virtual"`
+	Hypervisor string `json:"hypervisor,omitempty"`
+}
+
+// Problem is an RFC 7807 problem details response
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+// newAddress parses and resolves the provided mac address
+func newAddress(mac string) (*Address, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		countLookup("", err)
+		return nil, err
+	}
+
+	name, err := vendorOf(hw)
+	countLookup(name, err)
+	if err != nil {
+		return nil, err
+	}
+	hypervisor, err := m2v.Hypervisor(hw)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := hw[:3].String()
+	addr := &Address{
+		MAC:        mac,
+		Normalized: hw.String(),
+		Prefix:     prefix,
+		Flags: Flags{
+			Multicast:  hw[0]&0x01 != 0,
+			Broadcast:  isBroadcast(hw),
+			Local:      hw[0]&0x02 != 0,
+			Virtual:    hypervisor != "",
+			Hypervisor: hypervisor,
+		},
+	}
+	if name != "" {
+		addr.Vendor = &Vendor{Name: name, Prefix: prefix}
+	}
+	return addr, nil
+}
+
+func isBroadcast(hw net.HardwareAddr) bool {
+	for _, b := range hw {
+		if b != 0xff {
+			return false
+		}
+	}
+	return true
+}
+
+// newProblem initializes a new problem details response
+func newProblem(r *http.Request, status int, code, detail string) *Problem {
+	return &Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     code,
+	}
+}
+
+// lookupV1 resolves the mac address in the final path segment
+func lookupV1(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeProblem(w, newProblem(r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "the lookup resource only supports GET"))
+		return
+	}
+
+	mac := strings.TrimPrefix(r.URL.Path, apiPrefix+"lookup/")
+	var tag string
+	if hw, err := net.ParseMAC(mac); err == nil {
+		tag = etag(hw)
+	}
+	addr, err := newAddress(mac)
+	if err != nil {
+		writeProblem(w, newProblem(r, http.StatusBadRequest, codeInvalidMAC, err.Error()))
+		return
+	}
+
+	if cacheable(w, r, tag) {
+		return
+	}
+	writeJSON(w, http.StatusOK, addr)
+}
+
+// notFoundV1 responds to requests for undefined resources
+func notFoundV1(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, newProblem(r, http.StatusNotFound, codeNotFound, "no resource exists at "+r.URL.Path))
+}
+
+func writeProblem(w http.ResponseWriter, p *Problem) {
+	write(w, contentTypeProblem, p.Status, p)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	write(w, contentTypeJSON, status, v)
+}
+
+func write(w http.ResponseWriter, contentType string, status int, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		log.Println("failed to encode response: ", err)
+		contentType, status = contentTypeProblem, http.StatusInternalServerError
+		b, _ = json.Marshal(&Problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(status),
+			Status: status,
+			Code:   codeInternal,
+		})
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	if _, err := w.Write(append(b, '\n')); err != nil {
+		log.Println("failed to write response: ", err)
+	}
+}
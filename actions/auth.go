@@ -0,0 +1,145 @@
+package actions
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+
+	codeUnauthorized = "unauthorized"
+)
+
+var (
+	apiKeysFile string
+
+	// apiKeys are the digests of the accepted api keys, or nil when requests
+	// are not authenticated
+	apiKeys map[[sha256.Size]byte]bool
+
+	// unguarded are the operational endpoints that remain available to
+	// probes and scrapers without credentials or limits, along with the web
+	// interface, whose requests to the api are guarded
+	unguarded = map[string]bool{
+		healthPath:    true,
+		readinessPath: true,
+		metricsPath:   true,
+		openapiPath:   true,
+	}
+)
+
+// loadAPIKeys reads the api keys listed one per line in the named file,
+// ignoring blank lines and comments
+func loadAPIKeys(name string) (map[[sha256.Size]byte]bool, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open api keys")
+	}
+	defer f.Close()
+
+	keys := make(map[[sha256.Size]byte]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key := strings.TrimSpace(scanner.Text())
+		if key == "" || strings.HasPrefix(key, "#") {
+			continue
+		}
+		keys[sha256.Sum256([]byte(key))] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read api keys")
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no api keys found in " + name)
+	}
+	return keys, nil
+}
+
+// credential returns the api key presented in the X-API-Key header or as a
+// bearer token
+func credential(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+	auth := r.Header.Get("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// rejection is the reason a client was refused service
+type rejection struct {
+	status int
+	code   string
+	detail string
+	retry  time.Duration
+}
+
+// admit authenticates a client against the keys and rate limits it, returning
+// nil when the request may proceed. Authenticated clients are limited by their
+// key and all others by their address, so that attempts to guess a key are
+// limited too. Any client is authenticated when keys is nil
+func admit(ip, key string, keys map[[sha256.Size]byte]bool) *rejection {
+	client, authenticated := identify(ip, key, keys)
+
+	if limiter != nil {
+		if ok, wait := limiter.allow(client); !ok {
+			return &rejection{http.StatusTooManyRequests, codeRateLimited, "the request rate limit was exceeded", wait}
+		}
+	}
+
+	if keys != nil && !authenticated {
+		detail := "an api key is required"
+		if key != "" {
+			detail = "the api key is not valid"
+		}
+		return &rejection{status: http.StatusUnauthorized, code: codeUnauthorized, detail: detail}
+	}
+	return nil
+}
+
+// identify names a client by its key when authenticated and by its address
+// otherwise
+func identify(ip, key string, keys map[[sha256.Size]byte]bool) (string, bool) {
+	if key != "" && keys[sha256.Sum256([]byte(key))] {
+		return fmt.Sprintf("key:%x", sha256.Sum256([]byte(key))), true
+	}
+	return "ip:" + ip, false
+}
+
+// guard admits requests to all but the operational endpoints, requiring an
+// admin key of requests to the admin api
+func guard(next http.Handler) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if unguarded[r.URL.Path] || strings.HasPrefix(r.URL.Path, uiPath) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		keys := apiKeys
+		if strings.HasPrefix(r.URL.Path, adminPrefix) {
+			keys = adminKeys
+		}
+		if rej := admit(clientIP(r, proxies), credential(r), keys); rej != nil {
+			switch rej.status {
+			case http.StatusTooManyRequests:
+				w.Header().Set("Retry-After", fmt.Sprint(int64(math.Ceil(rej.retry.Seconds()))))
+			case http.StatusUnauthorized:
+				w.Header().Set("WWW-Authenticate", `Bearer realm="mac2vendor"`)
+			}
+			writeProblem(w, newProblem(r, rej.status, rej.code, rej.detail))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,88 @@
+package actions
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGuard(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mac2vnd")
+	if err != nil {
+		t.Fatal("failed to create directory: ", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "keys")
+	if err := ioutil.WriteFile(name, []byte("# operators\nsecret\n\nother\n"), 0600); err != nil {
+		t.Fatal("failed to write keys: ", err)
+	}
+	keys, err := loadAPIKeys(name)
+	if err != nil {
+		t.Fatal("failed to load keys: ", err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("loaded %d keys; expected 2", len(keys))
+	}
+
+	defer func(keys map[[sha256.Size]byte]bool, l *rateLimiter) {
+		apiKeys, limiter = keys, l
+	}(apiKeys, limiter)
+	apiKeys, limiter = keys, newRateLimiter(1, 2)
+
+	router := newRouter()
+	handler := guard(router)
+	request := func(path string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		header []string
+		code   int
+		error  string
+	}{
+		{"API Key", "/v1/lookup/84:38:35:77:aa:52", []string{apiKeyHeader, "secret"}, http.StatusOK, ""},
+		{"Bearer Token", "/v1/lookup/84:38:35:77:aa:52", []string{"Authorization", "Bearer other"}, http.StatusOK, ""},
+		{"Missing Key", "/v1/lookup/84:38:35:77:aa:52", nil, http.StatusUnauthorized, codeUnauthorized},
+		{"Invalid Key", "/84:38:35:77:aa:52", []string{apiKeyHeader, "guess"}, http.StatusUnauthorized, codeUnauthorized},
+		{"Guesses Limited", "/84:38:35:77:aa:52", []string{apiKeyHeader, "guess"}, http.StatusTooManyRequests, codeRateLimited},
+		{"Key Limited Separately", "/84:38:35:77:aa:52", []string{apiKeyHeader, "secret"}, http.StatusOK, ""},
+		{"Key Limited", "/84:38:35:77:aa:52", []string{apiKeyHeader, "secret"}, http.StatusTooManyRequests, codeRateLimited},
+		{"Probe", healthPath, nil, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := request(tt.path, tt.header...)
+			if w.Code != tt.code {
+				t.Fatalf("received unexpected status code: %v; expected %v", w.Code, tt.code)
+			}
+			if tt.error == "" {
+				return
+			}
+
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem.Code != tt.error {
+				t.Errorf("received unexpected problem: %s", w.Body)
+			}
+			if tt.code == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "1" {
+				t.Errorf("received unexpected retry after: %q", w.Header().Get("Retry-After"))
+			}
+			if tt.code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected an authentication challenge")
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package actions
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	contentTypeNDJSON = "application/x-ndjson"
+
+	codeInvalidBody   = "invalid_body"
+	codeInvalidItem   = "invalid_item"
+	codeBatchTooLarge = "batch_too_large"
+	codeUnsupported   = "unsupported_media_type"
+
+	// maxItemBytes bounds the size of a single encoded batch item
+	maxItemBytes = 256
+	// flushInterval is the number of batch results written between flushes
+	flushInterval = 100
+)
+
+var errBatchTooLarge = errors.New("batch too large")
+
+// BatchResult is the outcome of resolving a single item of a batch
+type BatchResult struct {
+	Index   int      `json:"index"`
+	Input   string   `json:"input"`
+	Address *Address `json:"address,omitempty"`
+	Error   *Problem `json:"error,omitempty"`
+}
+
+// lookupBatch resolves the json array or newline-delimited json stream of
+// mac addresses in the request body as it is read, writing the results in the
+// same format and order
+func lookupBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeProblem(w, newProblem(r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "the batch lookup resource only supports POST"))
+		return
+	}
+
+	mediaType := contentTypeJSON
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		var err error
+		if mediaType, _, err = mime.ParseMediaType(ct); err != nil {
+			writeProblem(w, newProblem(r, http.StatusUnsupportedMediaType, codeUnsupported, err.Error()))
+			return
+		}
+	}
+
+	var items batchDecoder
+	body := http.MaxBytesReader(w, r.Body, int64(maxBatch+1)*maxItemBytes)
+	switch mediaType {
+	case contentTypeJSON:
+		items = &arrayDecoder{dec: json.NewDecoder(body)}
+	case contentTypeNDJSON:
+		items = &ndjsonDecoder{scanner: bufio.NewScanner(body)}
+	default:
+		writeProblem(w, newProblem(r, http.StatusUnsupportedMediaType, codeUnsupported,
+			fmt.Sprintf("batches must be sent as %s or %s", contentTypeJSON, contentTypeNDJSON)))
+		return
+	}
+
+	// results are written while the body is still being read, which http/1.x
+	// servers only permit once full duplex is enabled. Other responses either
+	// are full duplex already or do not support it, which is left to fail
+	http.NewResponseController(w).EnableFullDuplex()
+	if err := writeBatch(w, r, mediaType, items); err != nil {
+		log.Println("failed to write response: ", err)
+	}
+}
+
+// batchDecoder reads the items of a batch one at a time, returning io.EOF
+// once every item was read
+type batchDecoder interface {
+	Next() (json.RawMessage, error)
+}
+
+// arrayDecoder reads the items of a json array
+type arrayDecoder struct {
+	dec     *json.Decoder
+	started bool
+}
+
+func (d *arrayDecoder) Next() (json.RawMessage, error) {
+	if !d.started {
+		d.started = true
+		if tok, err := d.dec.Token(); err != nil {
+			return nil, err
+		} else if tok != json.Delim('[') {
+			return nil, errors.New("expected a json array of mac addresses")
+		}
+	}
+
+	if !d.dec.More() {
+		if _, err := d.dec.Token(); err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	var item json.RawMessage
+	if err := d.dec.Decode(&item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// ndjsonDecoder reads the items of a newline-delimited json stream
+type ndjsonDecoder struct {
+	scanner *bufio.Scanner
+}
+
+func (d *ndjsonDecoder) Next() (json.RawMessage, error) {
+	for d.scanner.Scan() {
+		if line := bytes.TrimSpace(d.scanner.Bytes()); len(line) > 0 {
+			return json.RawMessage(line), nil
+		}
+	}
+	if err := d.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// writeBatch resolves and writes every item as it is read. Results are held
+// back until flushInterval of them are pending, so that smaller batches which
+// turn out to be malformed or too large are refused with a problem, while
+// clients consume larger ones incrementally. Once results were sent, such
+// failures are reported by a final result with an empty input, which carries
+// the problem and the index of the offending item.
+func writeBatch(w http.ResponseWriter, r *http.Request, mediaType string, items batchDecoder) error {
+	bw := &batchWriter{w: w, array: mediaType == contentTypeJSON}
+	bw.flusher, _ = w.(http.Flusher)
+	w.Header().Set("Content-Type", mediaType)
+
+	for i := 0; ; i++ {
+		item, err := items.Next()
+		if err == io.EOF {
+			return bw.close()
+		}
+		if err == nil && uint(i) >= maxBatch {
+			err = errBatchTooLarge
+		}
+		if _, ok := err.(*http.MaxBytesError); ok {
+			err = errBatchTooLarge
+		}
+
+		switch {
+		case err == errBatchTooLarge:
+			return bw.fail(r, i, newProblem(r, http.StatusRequestEntityTooLarge, codeBatchTooLarge,
+				fmt.Sprintf("batches are limited to %d addresses", maxBatch)))
+		case err != nil:
+			return bw.fail(r, i, newProblem(r, http.StatusBadRequest, codeInvalidBody, err.Error()))
+		}
+
+		if err := bw.write(resolveItem(r, i, item)); err != nil {
+			return err
+		}
+	}
+}
+
+// batchWriter buffers the results of a batch until they are flushed, which
+// commits the response
+type batchWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	buf     bytes.Buffer
+	array   bool
+	count   int
+	started bool
+}
+
+func (bw *batchWriter) write(result *BatchResult) error {
+	b, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !bw.array:
+	case bw.count == 0:
+		bw.buf.WriteByte('[')
+	default:
+		bw.buf.WriteByte(',')
+	}
+	bw.buf.Write(b)
+	if !bw.array {
+		bw.buf.WriteByte('\n')
+	}
+
+	bw.count++
+	if bw.count%flushInterval == 0 {
+		return bw.flush()
+	}
+	return nil
+}
+
+func (bw *batchWriter) flush() error {
+	if !bw.started {
+		bw.started = true
+		bw.w.WriteHeader(http.StatusOK)
+	}
+	_, err := bw.buf.WriteTo(bw.w)
+	if err == nil && bw.flusher != nil {
+		bw.flusher.Flush()
+	}
+	return err
+}
+
+// fail refuses the batch with the problem when no results were sent yet, and
+// otherwise reports it as the final result
+func (bw *batchWriter) fail(r *http.Request, index int, problem *Problem) error {
+	if !bw.started {
+		writeProblem(bw.w, problem)
+		return nil
+	}
+
+	problem.Instance = fmt.Sprintf("%s#%d", r.URL.Path, index)
+	if err := bw.write(&BatchResult{Index: index, Error: problem}); err != nil {
+		return err
+	}
+	return bw.close()
+}
+
+func (bw *batchWriter) close() error {
+	if bw.array {
+		if bw.count == 0 {
+			bw.buf.WriteByte('[')
+		}
+		bw.buf.WriteString("]\n")
+	}
+	return bw.flush()
+}
+
+// resolveItem resolves a single batch item, reporting invalid items in place
+func resolveItem(r *http.Request, index int, item json.RawMessage) *BatchResult {
+	result := &BatchResult{Index: index}
+	if err := json.Unmarshal(item, &result.Input); err != nil {
+		result.Input = string(item)
+		result.Error = newProblem(r, http.StatusBadRequest, codeInvalidItem, "batch items must be json strings")
+		result.Error.Instance = fmt.Sprintf("%s#%d", r.URL.Path, index)
+		return result
+	}
+
+	return resolveInput(r, index, result.Input)
+}
+
+// resolveInput resolves a single address of a batch or stream, reporting an
+// invalid address in place
+func resolveInput(r *http.Request, index int, input string) *BatchResult {
+	result := &BatchResult{Index: index, Input: input}
+	addr, err := newAddress(input)
+	if err != nil {
+		result.Error = newProblem(r, http.StatusBadRequest, codeInvalidMAC, err.Error())
+		result.Error.Instance = fmt.Sprintf("%s#%d", r.URL.Path, index)
+		return result
+	}
+	result.Address = addr
+	return result
+}
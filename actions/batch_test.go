@@ -0,0 +1,189 @@
+package actions
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLookupBatch(t *testing.T) {
+	post := func(contentType, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/lookup", strings.NewReader(body))
+		if contentType != "" {
+			r.Header.Set("Content-Type", contentType)
+		}
+		w := httptest.NewRecorder()
+		newRouter().ServeHTTP(w, r)
+		return w
+	}
+
+	verify := func(t *testing.T, results []BatchResult) {
+		if len(results) != 3 {
+			t.Fatalf("received %d results; expected 3", len(results))
+		}
+		for i, result := range results {
+			if result.Index != i {
+				t.Errorf("received result %d out of order: %+v", i, result)
+			}
+		}
+		if results[0].Address == nil || results[0].Address.Vendor == nil || results[0].Address.Vendor.Name != "Apple, Inc." {
+			t.Errorf("unexpected result: %+v", results[0])
+		}
+		if results[1].Error == nil || results[1].Error.Code != codeInvalidMAC || results[1].Address != nil {
+			t.Errorf("expected an invalid mac error, received %+v", results[1])
+		}
+		if results[2].Error == nil || results[2].Error.Code != codeInvalidItem {
+			t.Errorf("expected an invalid item error, received %+v", results[2])
+		}
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		w := post("application/json; charset=utf-8", `["84:38:35:77:aa:52", "not-a-mac", 42]`)
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != contentTypeJSON {
+			t.Fatalf("received unexpected response: %v %v", w.Code, w.Header().Get("Content-Type"))
+		}
+
+		var results []BatchResult
+		if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+			t.Fatal("failed to decode response: ", err)
+		}
+		verify(t, results)
+	})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		w := post(contentTypeNDJSON, "\"84:38:35:77:aa:52\"\n\"not-a-mac\"\n\n{}\n")
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != contentTypeNDJSON {
+			t.Fatalf("received unexpected response: %v %v", w.Code, w.Header().Get("Content-Type"))
+		}
+
+		var results []BatchResult
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var result BatchResult
+			if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+				t.Fatal("failed to decode result: ", err)
+			}
+			results = append(results, result)
+		}
+		verify(t, results)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		if w := post("", `[]`); w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "[]" {
+			t.Errorf("received unexpected response: %v %s", w.Code, w.Body)
+		}
+	})
+
+	failures := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		code        int
+		problem     string
+	}{
+		{"Too Large", http.MethodPost, contentTypeJSON, `["84:38:35:77:aa:52", "84:38:35:77:aa:53", "84:38:35:77:aa:54"]`, http.StatusRequestEntityTooLarge, codeBatchTooLarge},
+		{"Malformed", http.MethodPost, contentTypeJSON, `["84:38:35:77:aa:52"`, http.StatusBadRequest, codeInvalidBody},
+		{"Not An Array", http.MethodPost, contentTypeJSON, `{"mac": "84:38:35:77:aa:52"}`, http.StatusBadRequest, codeInvalidBody},
+		{"Unsupported Media Type", http.MethodPost, "text/csv", "84:38:35:77:aa:52", http.StatusUnsupportedMediaType, codeUnsupported},
+		{"Unsupported Method", http.MethodGet, "", "", http.StatusMethodNotAllowed, codeMethodNotAllowed},
+	}
+	defer func(max uint) {
+		maxBatch = max
+	}(maxBatch)
+	maxBatch = 2
+
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/v1/lookup", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+			newRouter().ServeHTTP(w, r)
+
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatal("failed to decode problem: ", err)
+			}
+			if w.Code != tt.code || problem.Code != tt.problem {
+				t.Errorf("received unexpected problem: %v %+v; expected %v %v", w.Code, problem, tt.code, tt.problem)
+			}
+		})
+	}
+	t.Run("Streamed", func(t *testing.T) {
+		maxBatch = 2 * flushInterval
+		body := strings.Repeat("\"84:38:35:77:aa:52\"\n", int(maxBatch)+1)
+		w := post(contentTypeNDJSON, body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("received unexpected response: %v %s", w.Code, w.Body)
+		}
+
+		var results []BatchResult
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var result BatchResult
+			if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+				t.Fatal("failed to decode result: ", err)
+			}
+			results = append(results, result)
+		}
+		if len(results) != int(maxBatch)+1 {
+			t.Fatalf("received %d results; expected %d", len(results), maxBatch+1)
+		}
+		if last := results[maxBatch]; last.Index != int(maxBatch) || last.Error == nil || last.Error.Code != codeBatchTooLarge {
+			t.Errorf("expected the batch to end with a too large problem, received %+v", last)
+		}
+
+		w = post(contentTypeJSON, "["+strings.Repeat(`"84:38:35:77:aa:52",`, flushInterval)+"42")
+		var items []BatchResult
+		if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil {
+			t.Fatal("failed to decode response: ", err)
+		}
+		if last := items[len(items)-1]; w.Code != http.StatusOK || last.Error == nil || last.Error.Code != codeInvalidBody {
+			t.Errorf("expected the batch to end with an invalid body problem, received %v %+v", w.Code, last)
+		}
+	})
+}
+
+func TestLookupBatchServer(t *testing.T) {
+	srv := httptest.NewServer(newServer().Handler)
+	defer srv.Close()
+
+	n := 9 * flushInterval
+	body := strings.Repeat("\"84:38:35:77:aa:52\"\n", n)
+	bodies := []struct {
+		name string
+		body io.Reader
+	}{
+		{"Sized", strings.NewReader(body)},
+		{"Chunked", struct{ io.Reader }{strings.NewReader(body)}},
+	}
+	for _, tt := range bodies {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := http.Post(srv.URL+"/v1/lookup", contentTypeNDJSON, tt.body)
+			if err != nil {
+				t.Fatal("failed to post batch: ", err)
+			}
+			defer res.Body.Close()
+
+			count := 0
+			scanner := bufio.NewScanner(res.Body)
+			for scanner.Scan() {
+				var result BatchResult
+				if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+					t.Fatal("failed to decode result: ", err)
+				}
+				if result.Index != count || result.Error != nil {
+					t.Fatalf("received unexpected result %d: %+v", count, result)
+				}
+				count++
+			}
+			if res.StatusCode != http.StatusOK || count != n {
+				t.Errorf("received %d results with status %v; expected %d", count, res.StatusCode, n)
+			}
+		})
+	}
+}
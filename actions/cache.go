@@ -0,0 +1,55 @@
+package actions
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+)
+
+// cacheMaxAge is the duration for which clients may cache lookup responses
+var cacheMaxAge = time.Hour
+
+// etag identifies the representation of an address, which only changes
+// with the dataset, the prefix of the address, or the override applying to
+// it. Addresses with an override of their own are tagged individually. Tags
+// are derived before the address is resolved, so that concurrent changes
+// result in a tag that is already stale rather than in stale content under
+// a current tag.
+func etag(hw net.HardwareAddr) string {
+	version := m2v.Version()
+	if e, ok := overrides.Load().Find(hw); ok {
+		digest := sha256.Sum256([]byte(e.Vendor))
+		return fmt.Sprintf(`"%s-%s-%x"`, version, strings.Replace(e.Prefix, ":", "", -1), digest[:4])
+	}
+	return fmt.Sprintf(`"%s-%x"`, version, []byte(hw[:3]))
+}
+
+// cacheable sets the caching headers of a lookup response, reporting whether
+// the request was answered with 304 Not Modified because the client already
+// holds the current representation
+func cacheable(w http.ResponseWriter, r *http.Request, tag string) bool {
+	w.Header().Set("ETag", tag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(cacheMaxAge/time.Second)))
+	if !matches(r.Header.Get("If-None-Match"), tag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// matches reports whether the If-None-Match header lists the entity tag,
+// using the weak comparison required for conditional GET requests
+func matches(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
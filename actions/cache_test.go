@@ -0,0 +1,103 @@
+package actions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+)
+
+func TestCache(t *testing.T) {
+	defer func(age time.Duration) {
+		cacheMaxAge = age
+	}(cacheMaxAge)
+	cacheMaxAge = 5 * time.Minute
+
+	get := func(path, tag string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if tag != "" {
+			r.Header.Set("If-None-Match", tag)
+		}
+		w := httptest.NewRecorder()
+		newRouter().ServeHTTP(w, r)
+		return w
+	}
+
+	for _, path := range []string{"/v1/lookup/84:38:35:77:aa:52", "/84:38:35:77:aa:52"} {
+		t.Run(path, func(t *testing.T) {
+			w := get(path, "")
+			tag := w.Header().Get("ETag")
+			if w.Code != http.StatusOK || tag == "" {
+				t.Fatalf("expected a tagged response, received %v %q", w.Code, tag)
+			}
+			if actual := w.Header().Get("Cache-Control"); actual != "public, max-age=300" {
+				t.Errorf("received unexpected cache control: %q", actual)
+			}
+
+			tests := []struct {
+				name string
+				tag  string
+				code int
+			}{
+				{"Match", tag, http.StatusNotModified},
+				{"Weak Match", "W/" + tag, http.StatusNotModified},
+				{"List", `"other", ` + tag, http.StatusNotModified},
+				{"Wildcard", "*", http.StatusNotModified},
+				{"Mismatch", `"other"`, http.StatusOK},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					w := get(path, tt.tag)
+					if w.Code != tt.code {
+						t.Errorf("received unexpected status code: %v; expected %v", w.Code, tt.code)
+					}
+					if tt.code == http.StatusNotModified && w.Body.Len() != 0 {
+						t.Errorf("expected an empty body, received %q", w.Body.String())
+					}
+				})
+			}
+		})
+	}
+
+	t.Run("Prefix", func(t *testing.T) {
+		same := get("/v1/lookup/84:38:35:00:00:01", "").Header().Get("ETag")
+		other := get("/v1/lookup/00:00:0c:00:00:01", "").Header().Get("ETag")
+		if tag := get("/v1/lookup/84:38:35:77:aa:52", "").Header().Get("ETag"); tag != same || tag == other {
+			t.Errorf("expected tags to be derived from the prefix: %q, %q, %q", tag, same, other)
+		}
+	})
+
+	t.Run("Overrides", func(t *testing.T) {
+		defer overrides.Store(overrides.Load())
+		tag := func(mac string) string {
+			return get("/v1/lookup/"+mac, "").Header().Get("ETag")
+		}
+		before := tag("84:38:35:77:aa:52")
+
+		o := m2v.NewOverrides()
+		o.Set("84:38:35:77:aa:52", "Lab Device")
+		overrides.Store(o)
+		assigned := tag("84:38:35:77:aa:52")
+		if assigned == before || tag("84:38:35:00:00:01") != before {
+			t.Errorf("expected only the overridden address to be tagged anew: %q, %q", before, assigned)
+		}
+
+		o = cloneOverrides(o)
+		o.Set("84:38:35:77:aa:52", "Test Device")
+		overrides.Store(o)
+		if changed := tag("84:38:35:77:aa:52"); changed == assigned || changed == before {
+			t.Errorf("expected a changed override to be tagged anew: %q, %q", assigned, changed)
+		}
+		if w := get("/v1/lookup/84:38:35:77:aa:52", assigned); w.Code != http.StatusOK {
+			t.Errorf("expected a changed override to be sent, received %v", w.Code)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		if w := get("/v1/lookup/invalid", "*"); w.Code != http.StatusBadRequest || w.Header().Get("ETag") != "" {
+			t.Errorf("expected errors not to be cached, received %v %q", w.Code, w.Header().Get("ETag"))
+		}
+	})
+}
@@ -0,0 +1,349 @@
+package actions
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// pcap magic numbers for microsecond and nanosecond timestamps
+	pcapMagicMicros = 0xa1b2c3d4
+	pcapMagicNanos  = 0xa1b23c4d
+
+	// pcapng block types and options
+	pcapngSectionHeader    = 0x0a0d0d0a
+	pcapngInterfaceDesc    = 0x00000001
+	pcapngObsoletePacket   = 0x00000002
+	pcapngSimplePacket     = 0x00000003
+	pcapngEnhancedPacket   = 0x00000006
+	pcapngByteOrderMagic   = 0x1a2b3c4d
+	pcapngOptionEnd        = 0
+	pcapngOptionTSResol    = 9
+	pcapngDefaultTSResol   = 6
+	pcapngMaxBlockLength   = 16 << 20
+	pcapMaxCaptureLength   = 16 << 20
+	pcapRecordHeaderLength = 16
+
+	// link layer types, see https://www.tcpdump.org/linktypes.html
+	linkTypeEthernet      = 1
+	linkType80211         = 105
+	linkType80211Radiotap = 127
+)
+
+var errNotCapture = errors.New("not a pcap or pcapng file")
+
+// frame is a captured link layer frame
+type frame struct {
+	linkType  uint16
+	timestamp time.Time
+	data      []byte
+}
+
+// frameReader reads the frames of a capture file
+type frameReader interface {
+	// next returns the next frame of the capture or io.EOF
+	next() (frame, error)
+}
+
+// newFrameReader detects the format of the capture from its magic number
+func newFrameReader(r io.Reader) (frameReader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil {
+		return nil, errNotCapture
+	}
+
+	if binary.LittleEndian.Uint32(magic) == pcapngSectionHeader {
+		return &pcapngReader{r: br}, nil
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		switch order.Uint32(magic) {
+		case pcapMagicMicros:
+			return newPcapReader(br, order, time.Microsecond)
+		case pcapMagicNanos:
+			return newPcapReader(br, order, time.Nanosecond)
+		}
+	}
+	return nil, errNotCapture
+}
+
+// pcapReader reads the classic libpcap file format
+type pcapReader struct {
+	r        io.Reader
+	order    binary.ByteOrder
+	unit     time.Duration
+	linkType uint16
+}
+
+func newPcapReader(r io.Reader, order binary.ByteOrder, unit time.Duration) (*pcapReader, error) {
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, errors.Wrap(err, "failed to read pcap header")
+	}
+	return &pcapReader{
+		r:        r,
+		order:    order,
+		unit:     unit,
+		linkType: uint16(order.Uint32(header[20:24])),
+	}, nil
+}
+
+func (p *pcapReader) next() (frame, error) {
+	header := make([]byte, pcapRecordHeaderLength)
+	if _, err := io.ReadFull(p.r, header); err == io.EOF {
+		return frame{}, io.EOF
+	} else if err != nil {
+		return frame{}, errors.Wrap(err, "failed to read pcap record")
+	}
+
+	length := p.order.Uint32(header[8:12])
+	if length > pcapMaxCaptureLength {
+		return frame{}, errors.Errorf("invalid pcap record length %d", length)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(p.r, data); err != nil {
+		return frame{}, errors.Wrap(err, "failed to read pcap record")
+	}
+
+	sec := int64(p.order.Uint32(header[0:4]))
+	frac := int64(p.order.Uint32(header[4:8]))
+	return frame{
+		linkType:  p.linkType,
+		timestamp: time.Unix(sec, frac*int64(p.unit)).UTC(),
+		data:      data,
+	}, nil
+}
+
+// pcapngInterface is the state of an interface description block
+type pcapngInterface struct {
+	linkType uint16
+	// units is the number of timestamp units per second
+	units uint64
+}
+
+// pcapngReader reads the pcap next generation file format
+type pcapngReader struct {
+	r          io.Reader
+	order      binary.ByteOrder
+	interfaces []pcapngInterface
+}
+
+func (p *pcapngReader) next() (frame, error) {
+	for {
+		blockType, body, err := p.readBlock()
+		if err != nil {
+			return frame{}, err
+		}
+
+		switch blockType {
+		case pcapngInterfaceDesc:
+			if len(body) < 8 {
+				return frame{}, errors.New("truncated pcapng interface description")
+			}
+			units, err := p.resolution(body[8:])
+			if err != nil {
+				return frame{}, err
+			}
+			p.interfaces = append(p.interfaces, pcapngInterface{
+				linkType: p.order.Uint16(body[0:2]),
+				units:    units,
+			})
+		case pcapngEnhancedPacket:
+			if len(body) < 20 {
+				return frame{}, errors.New("truncated pcapng enhanced packet")
+			}
+			return p.packet(p.order.Uint32(body[0:4]), p.timestamp(body[4:12]), body[12:16], body[20:])
+		case pcapngObsoletePacket:
+			if len(body) < 20 {
+				return frame{}, errors.New("truncated pcapng packet")
+			}
+			return p.packet(uint32(p.order.Uint16(body[0:2])), p.timestamp(body[4:12]), body[12:16], body[20:])
+		case pcapngSimplePacket:
+			if len(body) < 4 {
+				return frame{}, errors.New("truncated pcapng simple packet")
+			}
+			// simple packets are captured on the first interface without a timestamp
+			f, err := p.packet(0, 0, body[0:4], body[4:])
+			f.timestamp = time.Time{}
+			return f, err
+		}
+	}
+}
+
+// readBlock reads the next block, returning its type and body
+func (p *pcapngReader) readBlock() (uint32, []byte, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(p.r, header); err == io.EOF {
+		return 0, nil, io.EOF
+	} else if err != nil {
+		return 0, nil, errors.Wrap(err, "failed to read pcapng block")
+	}
+
+	blockType := binary.LittleEndian.Uint32(header[0:4])
+	if blockType == pcapngSectionHeader {
+		bom := make([]byte, 4)
+		if _, err := io.ReadFull(p.r, bom); err != nil {
+			return 0, nil, errors.Wrap(err, "failed to read pcapng section header")
+		}
+		switch {
+		case binary.LittleEndian.Uint32(bom) == pcapngByteOrderMagic:
+			p.order = binary.LittleEndian
+		case binary.BigEndian.Uint32(bom) == pcapngByteOrderMagic:
+			p.order = binary.BigEndian
+		default:
+			return 0, nil, errors.New("invalid pcapng byte order magic")
+		}
+		p.interfaces = nil
+		header = append(header, bom...)
+	} else if p.order == nil {
+		return 0, nil, errNotCapture
+	}
+
+	length := p.order.Uint32(header[4:8])
+	if length < uint32(len(header))+4 || length%4 != 0 || length > pcapngMaxBlockLength {
+		return 0, nil, errors.Errorf("invalid pcapng block length %d", length)
+	}
+
+	block := make([]byte, int(length)-len(header))
+	if _, err := io.ReadFull(p.r, block); err != nil {
+		return 0, nil, errors.Wrap(err, "failed to read pcapng block")
+	}
+	return p.order.Uint32(header[0:4]), block[:len(block)-4], nil
+}
+
+// packet builds the frame captured on the interface with the given id
+func (p *pcapngReader) packet(id uint32, ts uint64, capLen []byte, data []byte) (frame, error) {
+	if int(id) >= len(p.interfaces) {
+		return frame{}, errors.Errorf("pcapng packet references unknown interface %d", id)
+	}
+
+	length := p.order.Uint32(capLen)
+	if length > uint32(len(data)) {
+		// simple packet blocks only record the original length
+		length = uint32(len(data))
+	}
+
+	// units are at most a billion per second, so that the remainder scaled to
+	// nanoseconds does not overflow
+	iface := p.interfaces[id]
+	sec := ts / iface.units
+	if sec > math.MaxInt64 {
+		return frame{}, errors.Errorf("pcapng timestamp %d out of range", ts)
+	}
+	nsec := (ts % iface.units) * uint64(time.Second) / iface.units
+	return frame{
+		linkType:  iface.linkType,
+		timestamp: time.Unix(int64(sec), int64(nsec)).UTC(),
+		data:      data[:length],
+	}, nil
+}
+
+func (p *pcapngReader) timestamp(b []byte) uint64 {
+	return uint64(p.order.Uint32(b[0:4]))<<32 | uint64(p.order.Uint32(b[4:8]))
+}
+
+// resolution parses the timestamp resolution from the options of an
+// interface description block, as the number of units per second, refusing
+// resolutions finer than a nanosecond
+func (p *pcapngReader) resolution(options []byte) (uint64, error) {
+	tsresol := byte(pcapngDefaultTSResol)
+	for len(options) >= 4 {
+		code := p.order.Uint16(options[0:2])
+		length := int(p.order.Uint16(options[2:4]))
+		if code == pcapngOptionEnd || 4+length > len(options) {
+			break
+		}
+		if code == pcapngOptionTSResol && length >= 1 {
+			tsresol = options[4]
+		}
+		if next := 4 + (length+3)&^3; next < len(options) {
+			options = options[next:]
+		} else {
+			break
+		}
+	}
+
+	units := uint64(1)
+	for i := byte(0); i < tsresol&0x7f; i++ {
+		if tsresol&0x80 != 0 {
+			units *= 2
+		} else {
+			units *= 10
+		}
+		if units > uint64(time.Second) {
+			return 0, errors.Errorf("unsupported pcapng timestamp resolution %#x", tsresol)
+		}
+	}
+	return units, nil
+}
+
+// frameAddresses extracts the source and destination addresses of the frame,
+// either of which is nil when not present in the frame
+func frameAddresses(f frame) (src, dst net.HardwareAddr) {
+	switch f.linkType {
+	case linkTypeEthernet:
+		if len(f.data) >= 12 {
+			return net.HardwareAddr(f.data[6:12]), net.HardwareAddr(f.data[0:6])
+		}
+	case linkType80211Radiotap:
+		if len(f.data) >= 4 {
+			length := int(binary.LittleEndian.Uint16(f.data[2:4]))
+			if length <= len(f.data) {
+				return dot11Addresses(f.data[length:])
+			}
+		}
+	case linkType80211:
+		return dot11Addresses(f.data)
+	}
+	return nil, nil
+}
+
+// dot11Addresses extracts the source and destination addresses of an 802.11
+// frame according to its type and distribution system bits
+func dot11Addresses(b []byte) (src, dst net.HardwareAddr) {
+	if len(b) < 10 {
+		return nil, nil
+	}
+
+	addr := func(n int) net.HardwareAddr {
+		offset := 4 + 6*(n-1)
+		if n == 4 {
+			offset = 24
+		}
+		if len(b) < offset+6 {
+			return nil
+		}
+		return net.HardwareAddr(b[offset : offset+6])
+	}
+
+	frameType := (b[0] >> 2) & 0x03
+	subtype := b[0] >> 4
+	switch frameType {
+	case 0: // management
+		return addr(2), addr(1)
+	case 1: // control frames; clear to send and acknowledgements carry no transmitter
+		if subtype == 12 || subtype == 13 {
+			return nil, addr(1)
+		}
+		return addr(2), addr(1)
+	case 2: // data
+		switch b[1] & 0x03 {
+		case 0x00:
+			return addr(2), addr(1)
+		case 0x01: // to ds
+			return addr(2), addr(3)
+		case 0x02: // from ds
+			return addr(3), addr(1)
+		default: // wireless distribution system
+			return addr(4), addr(3)
+		}
+	}
+	return nil, nil
+}
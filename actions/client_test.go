@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	m2v "github.com/n3integration/mac2vendor"
+	"github.com/n3integration/mac2vendor/client"
+)
+
+func TestServeClient(t *testing.T) {
+	srv := httptest.NewServer(newRouter())
+	defer srv.Close()
+
+	remote, err := client.New(srv.URL, client.WithCacheSize(0))
+	if err != nil {
+		t.Fatal("failed to initialize client: ", err)
+	}
+
+	ctx := context.Background()
+	macs := []string{"84:38:35:77:aa:52", "52:54:00:12:34:56", "00:00:0c:00:00:01"}
+	for name, svc := range map[string]m2v.Service{"local": m2v.Local(), "remote": remote} {
+		t.Run(name, func(t *testing.T) {
+			results, err := svc.LookupBatch(ctx, append(macs, "not-a-mac"))
+			if err != nil {
+				t.Fatal("failed to lookup batch: ", err)
+			}
+			for i, mac := range macs {
+				vendor, err := svc.Lookup(ctx, mac)
+				if err != nil || vendor != results[i].Vendor {
+					t.Errorf("received inconsistent vendors for %s: %q, %q", mac, vendor, results[i].Vendor)
+				}
+			}
+			if results[3].Err == nil {
+				t.Error("expected an invalid address to fail")
+			}
+
+			entries, err := svc.Search(ctx, "apple", 3)
+			if err != nil || !reflect.DeepEqual(entries, m2v.Search("apple")[:3]) {
+				t.Errorf("received unexpected entries: %+v %v", entries, err)
+			}
+
+			info, err := svc.Info(ctx)
+			if err != nil || info.Entries != m2v.Len() || info.Version != m2v.Version() || !info.Updated.Equal(m2v.Updated()) {
+				t.Errorf("received unexpected info: %+v %v", info, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package actions
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	corsOrigins string
+	corsMethods = "GET, HEAD, POST"
+	corsHeaders = "Authorization, Content-Type, If-None-Match, X-API-Key, X-Request-ID"
+	corsMaxAge  = 10 * time.Minute
+
+	// corsExposed are the response headers browsers reveal to scripts beyond
+	// the safelisted ones
+	corsExposed = strings.Join([]string{"ETag", "Retry-After", "WWW-Authenticate", requestIDHeader}, ", ")
+
+	// crossOrigin is the cross-origin policy, which is disabled when nil
+	crossOrigin *corsPolicy
+)
+
+// corsPolicy describes the origins permitted to call the service from a
+// browser, and the methods and headers they may use
+type corsPolicy struct {
+	any      bool
+	origins  map[string]bool
+	patterns [][2]string
+	methods  string
+	headers  string
+	maxAge   string
+}
+
+// newCORSPolicy parses comma separated origins, which may be *, exact origins
+// or patterns such as https://*.example.com, along with the methods and
+// headers permitted, and the duration for which preflights may be cached
+func newCORSPolicy(origins, methods, headers string, maxAge time.Duration) (*corsPolicy, error) {
+	p := &corsPolicy{
+		origins: make(map[string]bool),
+		methods: normalizeList(methods, strings.ToUpper),
+		headers: normalizeList(headers, http.CanonicalHeaderKey),
+		maxAge:  strconv.Itoa(int(maxAge.Seconds())),
+	}
+	for _, origin := range strings.Split(origins, ",") {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		switch {
+		case origin == "":
+		case origin == "*":
+			p.any = true
+		case strings.Count(origin, "*") == 1:
+			i := strings.Index(origin, "*")
+			p.patterns = append(p.patterns, [2]string{origin[:i], origin[i+1:]})
+		case strings.Contains(origin, "*"):
+			return nil, errors.Errorf("invalid cors origin: %s", origin)
+		default:
+			p.origins[strings.TrimSuffix(origin, "/")] = true
+		}
+	}
+	if !p.any && len(p.origins) == 0 && len(p.patterns) == 0 {
+		return nil, errors.New("no cors origins found in " + origins)
+	}
+	return p, nil
+}
+
+func normalizeList(list string, normalize func(string) string) string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, normalize(item))
+		}
+	}
+	return strings.Join(items, ", ")
+}
+
+// allows reports whether the origin may call the service
+func (p *corsPolicy) allows(origin string) bool {
+	origin = strings.ToLower(origin)
+	if p.any || p.origins[origin] {
+		return true
+	}
+	for _, pattern := range p.patterns {
+		if len(origin) > len(pattern[0])+len(pattern[1]) &&
+			strings.HasPrefix(origin, pattern[0]) && strings.HasSuffix(origin, pattern[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// cors answers preflight requests from permitted origins and allows their
+// scripts to read the responses of the service, including its rejections.
+// Requests from other origins are served without cors headers, which
+// browsers enforce. Every response varies by origin once a policy is
+// configured, so that shared caches do not serve a response without cors
+// headers to a permitted origin
+func cors(next http.Handler) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if crossOrigin == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		if origin == "" || !crossOrigin.allows(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if crossOrigin.any {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			w.Header().Set("Access-Control-Allow-Methods", crossOrigin.methods)
+			w.Header().Set("Access-Control-Allow-Headers", crossOrigin.headers)
+			w.Header().Set("Access-Control-Max-Age", crossOrigin.maxAge)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Access-Control-Expose-Headers", corsExposed)
+		next.ServeHTTP(w, r)
+	})
+}
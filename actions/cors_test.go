@@ -0,0 +1,109 @@
+package actions
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	defer func(p *corsPolicy, keys map[[sha256.Size]byte]bool) {
+		crossOrigin, apiKeys = p, keys
+	}(crossOrigin, apiKeys)
+
+	var err error
+	crossOrigin, err = newCORSPolicy("https://dashboard.example.com, https://*.internal.example.com", "get, post", "x-api-key, content-type", time.Hour)
+	if err != nil {
+		t.Fatal("failed to parse policy: ", err)
+	}
+	apiKeys = map[[sha256.Size]byte]bool{sha256.Sum256([]byte("secret")): true}
+
+	router := newRouter()
+	handler := instrument(router, cors(guard(router)))
+	request := func(method, origin string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/v1/lookup/84:38:35:77:aa:52", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("Preflight", func(t *testing.T) {
+		for _, origin := range []string{"https://dashboard.example.com", "https://grafana.internal.example.com"} {
+			w := request(http.MethodOptions, origin,
+				"Access-Control-Request-Method", http.MethodGet,
+				"Access-Control-Request-Headers", "x-api-key")
+			if w.Code != http.StatusNoContent {
+				t.Errorf("%s: expected the preflight to be answered without credentials, received %v", origin, w.Code)
+			}
+			expected := map[string]string{
+				"Access-Control-Allow-Origin":  origin,
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "X-Api-Key, Content-Type",
+				"Access-Control-Max-Age":       "3600",
+			}
+			for name, value := range expected {
+				if w.Header().Get(name) != value {
+					t.Errorf("%s: received %s %q; expected %q", origin, name, w.Header().Get(name), value)
+				}
+			}
+		}
+	})
+
+	t.Run("Disallowed Origin", func(t *testing.T) {
+		for _, origin := range []string{"https://evil.example.com", "https://.internal.example.com", "http://dashboard.example.com"} {
+			w := request(http.MethodOptions, origin, "Access-Control-Request-Method", http.MethodGet)
+			if w.Header().Get("Access-Control-Allow-Origin") != "" || w.Header().Get("Access-Control-Allow-Methods") != "" {
+				t.Errorf("%s: expected the origin to be refused: %v", origin, w.Header())
+			}
+			if w.Header().Get("Vary") != "Origin" {
+				t.Errorf("%s: expected responses to vary by origin", origin)
+			}
+		}
+	})
+
+	t.Run("Request", func(t *testing.T) {
+		w := request(http.MethodGet, "https://dashboard.example.com", apiKeyHeader, "secret")
+		if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://dashboard.example.com" {
+			t.Errorf("received unexpected response: %v %v", w.Code, w.Header())
+		}
+		if w.Header().Get("Access-Control-Expose-Headers") == "" {
+			t.Error("expected headers to be exposed to scripts")
+		}
+
+		// rejections remain readable by scripts of permitted origins
+		w = request(http.MethodGet, "https://dashboard.example.com")
+		if w.Code != http.StatusUnauthorized || w.Header().Get("Access-Control-Allow-Origin") == "" {
+			t.Errorf("received unexpected rejection: %v %v", w.Code, w.Header())
+		}
+
+		w = request(http.MethodGet, "", apiKeyHeader, "secret")
+		if w.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Error("expected same-origin requests to be served without cors headers")
+		}
+		if w.Header().Get("Vary") != "Origin" {
+			t.Error("expected same-origin responses to vary by origin so that shared caches keep them apart")
+		}
+	})
+
+	t.Run("Any Origin", func(t *testing.T) {
+		crossOrigin, _ = newCORSPolicy("*", corsMethods, corsHeaders, corsMaxAge)
+		w := request(http.MethodOptions, "https://anywhere.example.org", "Access-Control-Request-Method", http.MethodPost)
+		if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "*" || w.Header().Get("Access-Control-Max-Age") != "600" {
+			t.Errorf("received unexpected response: %v %v", w.Code, w.Header())
+		}
+	})
+
+	for _, origins := range []string{"", " , ", "https://*.*.example.com"} {
+		if _, err := newCORSPolicy(origins, corsMethods, corsHeaders, corsMaxAge); err == nil {
+			t.Errorf("expected origins %q to be rejected", origins)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package actions
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+	"github.com/pkg/errors"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	dnsOUILabel = "oui"
+	dnsMACLabel = "mac"
+
+	// dnsUDPSize is the largest response sent over udp, beyond which
+	// responses are truncated so that clients retry over tcp
+	dnsUDPSize = 512
+)
+
+var (
+	dnsAddr string
+	dnsZone = "mac2vendor."
+	// dnsMaxConns bounds the number of tcp connections served at once,
+	// beyond which connections are closed as soon as they are accepted, and
+	// is unlimited when zero
+	dnsMaxConns uint = 256
+
+	errNotInZone = errors.New("name is not within the zone")
+)
+
+// dnsServer answers vendor lookups over udp and tcp. Names within the zone
+// take the forms
+//
+//	843835.oui.<zone>               the vendor of a prefix
+//	84-38-35-77-aa-52.mac.<zone>    the vendor of an address
+//	35.38.84.oui.<zone>             the octets of either in reverse order
+//
+// which answer TXT queries with the vendor name and PTR queries with the
+// vendor name as a domain name. The zone itself answers SOA queries.
+type dnsServer struct {
+	zone  dnsmessage.Name
+	soa   dnsmessage.SOAResource
+	udp   net.PacketConn
+	tcp   net.Listener
+	conns chan struct{}
+	wg    sync.WaitGroup
+}
+
+// listenDNS binds the udp and tcp listeners of a dns server answering names
+// within the zone
+func listenDNS(addr, zone string) (*dnsServer, error) {
+	zone = strings.ToLower(strings.TrimSuffix(zone, ".")) + "."
+	name, err := dnsmessage.NewName(zone)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid dns zone")
+	}
+
+	s := &dnsServer{zone: name}
+	if dnsMaxConns > 0 {
+		s.conns = make(chan struct{}, dnsMaxConns)
+	}
+	s.soa = dnsmessage.SOAResource{
+		NS:      dnsmessage.MustNewName("ns." + zone),
+		MBox:    dnsmessage.MustNewName("hostmaster." + zone),
+		Refresh: 3600,
+		Retry:   600,
+		Expire:  86400,
+		MinTTL:  s.ttl(),
+	}
+
+	if s.udp, err = net.ListenPacket("udp", addr); err != nil {
+		return nil, errors.Wrap(err, "failed to listen on udp "+addr)
+	}
+	// bind tcp to the same port as udp when an ephemeral port was requested
+	if s.tcp, err = net.Listen("tcp", s.udp.LocalAddr().String()); err != nil {
+		s.udp.Close()
+		return nil, errors.Wrap(err, "failed to listen on tcp "+addr)
+	}
+	return s, nil
+}
+
+// Addr returns the address to which the server is bound
+func (s *dnsServer) Addr() net.Addr {
+	return s.udp.LocalAddr()
+}
+
+// Serve answers queries until the server is closed
+func (s *dnsServer) Serve() {
+	s.wg.Add(2)
+	go s.serveUDP()
+	go s.serveTCP()
+}
+
+// Close stops accepting queries and waits for in-flight queries to complete
+func (s *dnsServer) Close() error {
+	uerr := s.udp.Close()
+	terr := s.tcp.Close()
+	s.wg.Wait()
+	if uerr != nil {
+		return uerr
+	}
+	return terr
+}
+
+func (s *dnsServer) serveUDP() {
+	defer s.wg.Done()
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		res := s.answer(buf[:n], dnsUDPSize)
+		if res == nil {
+			continue
+		}
+		if _, err := s.udp.WriteTo(res, addr); err != nil {
+			log.Println("failed to write dns response: ", err)
+		}
+	}
+}
+
+func (s *dnsServer) serveTCP() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			return
+		}
+
+		if s.conns != nil {
+			select {
+			case s.conns <- struct{}{}:
+			default:
+				conn.Close()
+				continue
+			}
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			if s.conns != nil {
+				defer func() { <-s.conns }()
+			}
+			defer conn.Close()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// serveConn answers the length prefixed queries of a tcp connection until it
+// is idle for longer than the read timeout
+func (s *dnsServer) serveConn(conn net.Conn) {
+	var size [2]byte
+	for {
+		conn.SetDeadline(time.Now().Add(readTimeout))
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return
+		}
+		query := make([]byte, binary.BigEndian.Uint16(size[:]))
+		if _, err := io.ReadFull(conn, query); err != nil {
+			return
+		}
+
+		res := s.answer(query, 65535)
+		if res == nil {
+			return
+		}
+		binary.BigEndian.PutUint16(size[:], uint16(len(res)))
+		if _, err := conn.Write(append(size[:], res...)); err != nil {
+			return
+		}
+	}
+}
+
+// answer builds the response to a query, truncating it to the size limit,
+// or returns nil when the query is too malformed to respond to
+func (s *dnsServer) answer(query []byte, limit int) []byte {
+	var p dnsmessage.Parser
+	h, err := p.Start(query)
+	if err != nil || h.Response {
+		return nil
+	}
+
+	header := dnsmessage.Header{
+		ID:               h.ID,
+		Response:         true,
+		OpCode:           h.OpCode,
+		Authoritative:    true,
+		RecursionDesired: h.RecursionDesired,
+		RCode:            dnsmessage.RCodeSuccess,
+	}
+	q, err := p.Question()
+	switch {
+	case err != nil:
+		header.RCode = dnsmessage.RCodeFormatError
+		return s.build(header, nil, limit)
+	case h.OpCode != 0:
+		header.RCode = dnsmessage.RCodeNotImplemented
+		return s.build(header, &q, limit)
+	}
+
+	if s.isApex(q.Name) {
+		return s.build(header, &q, limit)
+	}
+
+	vendor, err := s.resolve(q.Name.String())
+	switch {
+	case err == errNotInZone:
+		header.RCode, header.Authoritative = dnsmessage.RCodeRefused, false
+		return s.build(header, &q, limit)
+	case err != nil || vendor == "":
+		header.RCode = dnsmessage.RCodeNameError
+		return s.build(header, &q, limit)
+	default:
+		return s.build(header, &q, limit, vendor)
+	}
+}
+
+// isApex returns whether the name is that of the zone itself
+func (s *dnsServer) isApex(name dnsmessage.Name) bool {
+	return strings.EqualFold(name.String(), s.zone.String())
+}
+
+// resolve returns the vendor registered for the prefix or address encoded in
+// the name, which is empty when the prefix is not registered
+func (s *dnsServer) resolve(name string) (string, error) {
+	name = strings.ToLower(name)
+	zone := s.zone.String()
+	if !strings.HasSuffix(name, "."+zone) {
+		return "", errNotInZone
+	}
+
+	labels := strings.Split(strings.TrimSuffix(name, "."+zone), ".")
+	kind, labels := labels[len(labels)-1], labels[:len(labels)-1]
+
+	var octets int
+	switch kind {
+	case dnsOUILabel:
+		octets = 3
+	case dnsMACLabel:
+		octets = 6
+	default:
+		return "", errors.Errorf("unsupported name: %s", name)
+	}
+
+	var digits string
+	if len(labels) == octets {
+		for i := len(labels) - 1; i >= 0; i-- {
+			digits += labels[i]
+		}
+	} else if len(labels) == 1 {
+		digits = strings.Replace(labels[0], "-", "", -1)
+	}
+
+	hw, err := hex.DecodeString(digits)
+	if err != nil || len(hw) != octets {
+		err = errors.Errorf("invalid %s: %s", kind, name)
+		countLookup("", err)
+		return "", err
+	}
+	if len(hw) < 6 {
+		hw = append(hw, 0, 0, 0)
+	}
+
+	vendor, err := vendorOf(net.HardwareAddr(hw))
+	countLookup(vendor, err)
+	return vendor, err
+}
+
+// build packs the response to the question, answering with the vendor when
+// one was resolved and otherwise including the zone authority
+func (s *dnsServer) build(header dnsmessage.Header, q *dnsmessage.Question, limit int, vendor ...string) []byte {
+	res, err := s.pack(header, q, vendor)
+	if err == nil && len(res) > limit {
+		header.Truncated = true
+		res, err = s.pack(header, q, nil)
+	}
+	if err != nil {
+		log.Println("failed to build dns response: ", err)
+		return nil
+	}
+	return res
+}
+
+func (s *dnsServer) pack(header dnsmessage.Header, q *dnsmessage.Question, vendor []string) ([]byte, error) {
+	b := dnsmessage.NewBuilder(make([]byte, 0, dnsUDPSize), header)
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if q == nil {
+		return b.Finish()
+	}
+	if err := b.Question(*q); err != nil {
+		return nil, err
+	}
+
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+	rh := dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: dnsmessage.ClassINET, TTL: s.ttl()}
+	answered := false
+	if s.isApex(q.Name) && (q.Type == dnsmessage.TypeSOA || q.Type == dnsmessage.TypeALL) && !header.Truncated {
+		rh.Type = dnsmessage.TypeSOA
+		if err := b.SOAResource(rh, s.soaRecord()); err != nil {
+			return nil, err
+		}
+		answered = true
+	}
+	if len(vendor) > 0 && !header.Truncated {
+		switch q.Type {
+		case dnsmessage.TypeTXT, dnsmessage.TypeALL:
+			rh.Type = dnsmessage.TypeTXT
+			if err := b.TXTResource(rh, dnsmessage.TXTResource{TXT: txtStrings(vendor[0])}); err != nil {
+				return nil, err
+			}
+			answered = true
+		case dnsmessage.TypePTR:
+			label := hostname(vendor[0])
+			if label == "" {
+				label = "unknown"
+			}
+			target, err := dnsmessage.NewName(label + "." + s.zone.String())
+			if err != nil {
+				return nil, err
+			}
+			if err := b.PTRResource(rh, dnsmessage.PTRResource{PTR: target}); err != nil {
+				return nil, err
+			}
+			answered = true
+		}
+	}
+
+	if !answered && !header.Truncated && header.RCode != dnsmessage.RCodeRefused {
+		if err := b.StartAuthorities(); err != nil {
+			return nil, err
+		}
+		soa := dnsmessage.ResourceHeader{Name: s.zone, Class: dnsmessage.ClassINET, TTL: s.ttl()}
+		if err := b.SOAResource(soa, s.soaRecord()); err != nil {
+			return nil, err
+		}
+	}
+	return b.Finish()
+}
+
+// soaRecord returns the start of authority of the zone, whose serial follows
+// the database as it is refreshed
+func (s *dnsServer) soaRecord() dnsmessage.SOAResource {
+	record := s.soa
+	record.Serial = uint32(m2v.Updated().Unix())
+	return record
+}
+
+// ttl is the duration for which resolvers may cache answers, in seconds
+func (s *dnsServer) ttl() uint32 {
+	return uint32(cacheMaxAge / time.Second)
+}
+
+// txtStrings splits the value into the 255 byte character strings of a TXT
+// record
+func txtStrings(v string) []string {
+	var strs []string
+	for len(v) > 255 {
+		strs = append(strs, v[:255])
+		v = v[255:]
+	}
+	return append(strs, v)
+}
+
+// hostname converts a vendor name into a domain name label, replacing runs
+// of characters that are not letters or digits with a hyphen
+func hostname(vendor string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(vendor) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			hyphen = false
+		} else if !hyphen && b.Len() > 0 {
+			b.WriteByte('-')
+			hyphen = true
+		}
+		if b.Len() >= 63 {
+			break
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
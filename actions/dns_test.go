@@ -0,0 +1,194 @@
+package actions
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// exchange sends a query to the dns server over the network and parses the
+// response
+func exchange(t *testing.T, network, addr, name string, qtype dnsmessage.Type) *dnsmessage.Message {
+	query := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 42, RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{Name: dnsmessage.MustNewName(name), Type: qtype, Class: dnsmessage.ClassINET},
+		},
+	}
+	b, err := query.Pack()
+	if err != nil {
+		t.Fatal("failed to pack query: ", err)
+	}
+
+	conn, err := net.DialTimeout(network, addr, time.Second)
+	if err != nil {
+		t.Fatal("failed to connect: ", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	res := make([]byte, 65535)
+	if network == "tcp" {
+		size := make([]byte, 2)
+		binary.BigEndian.PutUint16(size, uint16(len(b)))
+		if _, err := conn.Write(append(size, b...)); err != nil {
+			t.Fatal("failed to write query: ", err)
+		}
+		if _, err := io.ReadFull(conn, size); err != nil {
+			t.Fatal("failed to read response: ", err)
+		}
+		res = res[:binary.BigEndian.Uint16(size)]
+		if _, err := io.ReadFull(conn, res); err != nil {
+			t.Fatal("failed to read response: ", err)
+		}
+	} else {
+		if _, err := conn.Write(b); err != nil {
+			t.Fatal("failed to write query: ", err)
+		}
+		n, err := conn.Read(res)
+		if err != nil {
+			t.Fatal("failed to read response: ", err)
+		}
+		res = res[:n]
+	}
+
+	var msg dnsmessage.Message
+	if err := msg.Unpack(res); err != nil {
+		t.Fatal("failed to unpack response: ", err)
+	}
+	if msg.ID != query.ID || !msg.Response {
+		t.Errorf("received unexpected header: %+v", msg.Header)
+	}
+	return &msg
+}
+
+func TestDNS(t *testing.T) {
+	s, err := listenDNS("127.0.0.1:0", "Example")
+	if err != nil {
+		t.Fatal("failed to listen: ", err)
+	}
+	s.Serve()
+	defer s.Close()
+
+	tests := []struct {
+		name   string
+		qname  string
+		qtype  dnsmessage.Type
+		rcode  dnsmessage.RCode
+		answer string
+	}{
+		{"Prefix", "843835.oui.example.", dnsmessage.TypeTXT, dnsmessage.RCodeSuccess, "Apple, Inc."},
+		{"Address", "84-38-35-77-AA-52.mac.example.", dnsmessage.TypeTXT, dnsmessage.RCodeSuccess, "Apple, Inc."},
+		{"Reversed Address", "52.aa.77.35.38.84.mac.example.", dnsmessage.TypeTXT, dnsmessage.RCodeSuccess, "Apple, Inc."},
+		{"Pointer", "35.38.84.oui.example.", dnsmessage.TypePTR, dnsmessage.RCodeSuccess, "apple-inc.example."},
+		{"No Data", "843835.oui.example.", dnsmessage.TypeA, dnsmessage.RCodeSuccess, ""},
+		{"Unregistered", "525400.oui.example.", dnsmessage.TypeTXT, dnsmessage.RCodeNameError, ""},
+		{"Invalid", "8438.oui.example.", dnsmessage.TypeTXT, dnsmessage.RCodeNameError, ""},
+		{"Outside Zone", "843835.oui.example.org.", dnsmessage.TypeTXT, dnsmessage.RCodeRefused, ""},
+		{"Zone", "example.", dnsmessage.TypeSOA, dnsmessage.RCodeSuccess, "ns.example."},
+		{"Zone No Data", "Example.", dnsmessage.TypeNS, dnsmessage.RCodeSuccess, ""},
+	}
+	for _, network := range []string{"udp", "tcp"} {
+		for _, tt := range tests {
+			t.Run(network+" "+tt.name, func(t *testing.T) {
+				msg := exchange(t, network, s.Addr().String(), tt.qname, tt.qtype)
+				if msg.RCode != tt.rcode {
+					t.Fatalf("received unexpected rcode: %v; expected %v", msg.RCode, tt.rcode)
+				}
+				if tt.answer == "" {
+					if len(msg.Answers) != 0 {
+						t.Errorf("received unexpected answers: %v", msg.Answers)
+					}
+					if tt.rcode != dnsmessage.RCodeRefused && len(msg.Authorities) != 1 {
+						t.Errorf("expected the zone authority, received %v", msg.Authorities)
+					}
+					return
+				}
+
+				if len(msg.Answers) != 1 {
+					t.Fatalf("received %d answers; expected 1", len(msg.Answers))
+				}
+				var actual string
+				switch body := msg.Answers[0].Body.(type) {
+				case *dnsmessage.TXTResource:
+					actual = body.TXT[0]
+				case *dnsmessage.PTRResource:
+					actual = body.PTR.String()
+				case *dnsmessage.SOAResource:
+					actual = body.NS.String()
+				}
+				if actual != tt.answer {
+					t.Errorf("received %q; expected %q", actual, tt.answer)
+				}
+				if msg.Answers[0].Header.TTL != uint32(cacheMaxAge/time.Second) {
+					t.Errorf("received unexpected ttl: %d", msg.Answers[0].Header.TTL)
+				}
+			})
+		}
+	}
+}
+
+func TestDNSConnections(t *testing.T) {
+	defer func(n uint) {
+		dnsMaxConns = n
+	}(dnsMaxConns)
+	dnsMaxConns = 1
+
+	s, err := listenDNS("127.0.0.1:0", "example.")
+	if err != nil {
+		t.Fatal("failed to listen: ", err)
+	}
+	s.Serve()
+	defer s.Close()
+
+	query, _ := (&dnsmessage.Message{Questions: []dnsmessage.Question{
+		{Name: dnsmessage.MustNewName("example."), Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET},
+	}}).Pack()
+	// exchange connects to the server, which serves the connection once a
+	// query was answered over it
+	exchange := func(t *testing.T, s *dnsServer) net.Conn {
+		conn, err := net.DialTimeout("tcp", s.Addr().String(), time.Second)
+		if err != nil {
+			t.Fatal("failed to connect: ", err)
+		}
+		size := make([]byte, 2)
+		binary.BigEndian.PutUint16(size, uint16(len(query)))
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn.Write(append(size, query...)); err != nil {
+			t.Fatal("failed to write query: ", err)
+		}
+		if _, err := io.ReadFull(conn, size); err != nil {
+			t.Fatal("failed to read response: ", err)
+		}
+		return conn
+	}
+	defer exchange(t, s).Close()
+
+	refused, err := net.DialTimeout("tcp", s.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatal("failed to connect: ", err)
+	}
+	defer refused.Close()
+	refused.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := refused.Read(make([]byte, 2)); err == nil {
+		t.Error("expected a connection beyond the limit to be closed")
+	}
+
+	t.Run("Unlimited", func(t *testing.T) {
+		dnsMaxConns = 0
+		s, err := listenDNS("127.0.0.1:0", "example.")
+		if err != nil {
+			t.Fatal("failed to listen: ", err)
+		}
+		s.Serve()
+		defer s.Close()
+
+		for i := 0; i < 3; i++ {
+			defer exchange(t, s).Close()
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package actions
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+	"github.com/n3integration/mac2vendor/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcPort is the port of the grpc service, which is disabled when zero
+var grpcPort uint
+
+// grpcService implements the mac2vendor grpc service
+type grpcService struct {
+	rpc.UnimplementedMac2VendorServer
+}
+
+// newGRPCServer initializes the grpc service, along with the standard health
+// and reflection services, over tls when a configuration is provided
+func newGRPCServer(config *tls.Config) *grpc.Server {
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(guardUnary),
+		grpc.StreamInterceptor(guardStream),
+	}
+	if config != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config)))
+	}
+
+	srv := grpc.NewServer(opts...)
+	rpc.RegisterMac2VendorServer(srv, &grpcService{})
+
+	serving := healthpb.HealthCheckResponse_SERVING
+	if !m2v.IsLoaded() {
+		serving = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	checker := health.NewServer()
+	checker.SetServingStatus("", serving)
+	checker.SetServingStatus(rpc.Mac2Vendor_ServiceDesc.ServiceName, serving)
+	healthpb.RegisterHealthServer(srv, checker)
+
+	reflection.Register(srv)
+	return srv
+}
+
+// stopGRPC drains in-flight calls, forcibly closing any that remain after
+// the shutdown timeout
+func stopGRPC(srv *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout):
+		srv.Stop()
+	}
+}
+
+// Lookup resolves a single mac address
+func (s *grpcService) Lookup(_ context.Context, req *rpc.LookupRequest) (*rpc.LookupResponse, error) {
+	addr, err := newAddress(req.GetMac())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return &rpc.LookupResponse{Address: toRPCAddress(addr)}, nil
+}
+
+// BatchLookup resolves each address received on the stream in order
+func (s *grpcService) BatchLookup(stream rpc.Mac2Vendor_BatchLookupServer) error {
+	for index := uint64(0); ; index++ {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		res := &rpc.BatchLookupResponse{Index: index, Input: req.GetMac()}
+		if addr, err := newAddress(req.GetMac()); err != nil {
+			res.Result = &rpc.BatchLookupResponse_Error{
+				Error: &rpc.Error{Code: codeInvalidMAC, Detail: err.Error()},
+			}
+		} else {
+			res.Result = &rpc.BatchLookupResponse_Address{Address: toRPCAddress(addr)}
+		}
+
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+}
+
+// Search finds the vendor prefixes matching the query
+func (s *grpcService) Search(_ context.Context, req *rpc.SearchRequest) (*rpc.SearchResponse, error) {
+	if req.GetQuery() == "" {
+		return nil, status.Error(codes.InvalidArgument, "a search query is required")
+	}
+
+	entries := m2v.Search(req.GetQuery())
+	if limit := int(req.GetLimit()); limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	vendors := make([]*rpc.Vendor, 0, len(entries))
+	for _, e := range entries {
+		vendors = append(vendors, &rpc.Vendor{Name: e.Vendor, Prefix: e.Prefix})
+	}
+	return &rpc.SearchResponse{Vendors: vendors}, nil
+}
+
+// Info describes the vendor database
+func (s *grpcService) Info(context.Context, *rpc.InfoRequest) (*rpc.InfoResponse, error) {
+	return &rpc.InfoResponse{
+		Entries: uint64(m2v.Len()),
+		Updated: timestamppb.New(m2v.Updated()),
+		Version: m2v.Version(),
+	}, nil
+}
+
+func toRPCAddress(addr *Address) *rpc.Address {
+	res := &rpc.Address{
+		Mac:        addr.MAC,
+		Normalized: addr.Normalized,
+		Prefix:     addr.Prefix,
+		Flags: &rpc.Flags{
+			Multicast:  addr.Flags.Multicast,
+			Broadcast:  addr.Flags.Broadcast,
+			Local:      addr.Flags.Local,
+			Virtual:    addr.Flags.Virtual,
+			Hypervisor: addr.Flags.Hypervisor,
+		},
+	}
+	if addr.Vendor != nil {
+		res.Vendor = &rpc.Vendor{Name: addr.Vendor.Name, Prefix: addr.Vendor.Prefix}
+	}
+	return res
+}
+
+// guardUnary admits calls to the mac2vendor service
+func guardUnary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := admitCall(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// guardStream admits streams to the mac2vendor service
+func guardStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := admitCall(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// admitCall authenticates and rate limits calls to the mac2vendor service
+// using the api key presented in the x-api-key or authorization metadata,
+// leaving the health and reflection services open
+func admitCall(ctx context.Context, method string) error {
+	if !strings.HasPrefix(method, "/"+rpc.Mac2Vendor_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+		if host, _, err := net.SplitHostPort(ip); err == nil {
+			ip = host
+		}
+	}
+
+	r := &http.Request{Header: make(http.Header)}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, name := range []string{apiKeyHeader, "Authorization"} {
+			if values := md.Get(name); len(values) > 0 {
+				r.Header.Set(name, values[0])
+			}
+		}
+	}
+
+	rej := admit(ip, credential(r), apiKeys)
+	switch {
+	case rej == nil:
+		return nil
+	case rej.status == http.StatusTooManyRequests:
+		return status.Error(codes.ResourceExhausted, rej.detail)
+	default:
+		return status.Error(codes.Unauthenticated, rej.detail)
+	}
+}
@@ -0,0 +1,207 @@
+package actions
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/n3integration/mac2vendor/rpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// startGRPC serves the grpc service on a local port, returning a connected
+// client and a function stopping both
+func startGRPC(t *testing.T, config *tls.Config, creds credentials.TransportCredentials) (*grpc.ClientConn, func()) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("failed to listen: ", err)
+	}
+
+	srv := newGRPCServer(config)
+	go srv.Serve(ln)
+
+	conn, err := grpc.NewClient(ln.Addr().String(), grpc.WithTransportCredentials(creds))
+	if err != nil {
+		t.Fatal("failed to connect: ", err)
+	}
+	return conn, func() {
+		conn.Close()
+		stopGRPC(srv)
+	}
+}
+
+func TestGRPC(t *testing.T) {
+	conn, stop := startGRPC(t, nil, insecure.NewCredentials())
+	defer stop()
+
+	client := rpc.NewMac2VendorClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("Lookup", func(t *testing.T) {
+		res, err := client.Lookup(ctx, &rpc.LookupRequest{Mac: "84-38-35-77-AA-52"})
+		if err != nil {
+			t.Fatal("failed to lookup: ", err)
+		}
+		addr := res.GetAddress()
+		if addr.GetNormalized() != "84:38:35:77:aa:52" || addr.GetVendor().GetName() != "Apple, Inc." {
+			t.Errorf("received unexpected address: %v", addr)
+		}
+
+		if _, err := client.Lookup(ctx, &rpc.LookupRequest{Mac: "not-a-mac"}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected an invalid argument, received %v", err)
+		}
+	})
+
+	t.Run("BatchLookup", func(t *testing.T) {
+		stream, err := client.BatchLookup(ctx)
+		if err != nil {
+			t.Fatal("failed to open stream: ", err)
+		}
+		inputs := []string{"84:38:35:77:aa:52", "not-a-mac", "00:00:0c:00:00:01"}
+		for _, mac := range inputs {
+			if err := stream.Send(&rpc.LookupRequest{Mac: mac}); err != nil {
+				t.Fatal("failed to send: ", err)
+			}
+		}
+		stream.CloseSend()
+
+		var results []*rpc.BatchLookupResponse
+		for {
+			res, err := stream.Recv()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatal("failed to receive: ", err)
+			}
+			results = append(results, res)
+		}
+
+		if len(results) != len(inputs) {
+			t.Fatalf("received %d results; expected %d", len(results), len(inputs))
+		}
+		for i, res := range results {
+			if res.GetIndex() != uint64(i) || res.GetInput() != inputs[i] {
+				t.Errorf("received result out of order: %v", res)
+			}
+		}
+		if results[1].GetError().GetCode() != codeInvalidMAC || results[2].GetAddress().GetVendor() == nil {
+			t.Errorf("received unexpected results: %v", results)
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		res, err := client.Search(ctx, &rpc.SearchRequest{Query: "apple", Limit: 5})
+		if err != nil {
+			t.Fatal("failed to search: ", err)
+		}
+		if len(res.GetVendors()) != 5 || res.GetVendors()[0].GetName() != "Apple, Inc." {
+			t.Errorf("received unexpected vendors: %v", res.GetVendors())
+		}
+
+		res, err = client.Search(ctx, &rpc.SearchRequest{Query: "84-38-35"})
+		if err != nil || len(res.GetVendors()) != 1 || res.GetVendors()[0].GetPrefix() != "84:38:35" {
+			t.Errorf("received unexpected prefix search: %v %v", res, err)
+		}
+	})
+
+	t.Run("Info", func(t *testing.T) {
+		res, err := client.Info(ctx, &rpc.InfoRequest{})
+		if err != nil {
+			t.Fatal("failed to describe the database: ", err)
+		}
+		if res.GetEntries() == 0 || res.GetVersion() == "" || res.GetUpdated().AsTime().IsZero() {
+			t.Errorf("received unexpected info: %v", res)
+		}
+	})
+
+	t.Run("Health", func(t *testing.T) {
+		res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "mac2vendor.v1.Mac2Vendor"})
+		if err != nil || res.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("received unexpected health: %v %v", res, err)
+		}
+	})
+
+	t.Run("Authentication", func(t *testing.T) {
+		defer func(keys map[[sha256.Size]byte]bool) {
+			apiKeys = keys
+		}(apiKeys)
+		apiKeys = map[[sha256.Size]byte]bool{sha256.Sum256([]byte("secret")): true}
+
+		if _, err := client.Info(ctx, &rpc.InfoRequest{}); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("expected an unauthenticated call to be rejected, received %v", err)
+		}
+		authed := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret")
+		if _, err := client.Info(authed, &rpc.InfoRequest{}); err != nil {
+			t.Error("failed to call with an api key: ", err)
+		}
+		if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+			t.Error("expected health checks to remain open: ", err)
+		}
+	})
+}
+
+func TestGRPCMutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mac2vnd")
+	if err != nil {
+		t.Fatal("failed to create directory: ", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCA(t)
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	cert, key := ca.issue(t, 2, x509.ExtKeyUsageServerAuth)
+	writeFile(t, certFile, cert, time.Now())
+	writeFile(t, keyFile, key, time.Now())
+	writeFile(t, caFile, ca.pem, time.Now())
+
+	config, err := newTLSConfig(certFile, keyFile, caFile, "1.2")
+	if err != nil {
+		t.Fatal("failed to configure tls: ", err)
+	}
+
+	clientCert, clientKey := ca.issue(t, 3, x509.ExtKeyUsageClientAuth)
+	pair, err := tls.X509KeyPair(clientCert, clientKey)
+	if err != nil {
+		t.Fatal("failed to load client certificate: ", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	conn, stop := startGRPC(t, config, credentials.NewTLS(&tls.Config{RootCAs: roots, Certificates: []tls.Certificate{pair}}))
+	defer stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := rpc.NewMac2VendorClient(conn).Info(ctx, &rpc.InfoRequest{}); err != nil {
+		t.Error("failed to call over mutual tls: ", err)
+	}
+}
+
+func TestListenHost(t *testing.T) {
+	tests := map[string]string{
+		":9000":                  "",
+		"127.0.0.1:9000":         "127.0.0.1",
+		"[::1]:9000":             "::1",
+		unixPrefix + "/run/sock": "localhost",
+	}
+	for addr, expected := range tests {
+		if actual := listenHost(addr); actual != expected {
+			t.Errorf("received host %q for %s; expected %q", actual, addr, expected)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package actions
+
+import (
+	"net/http"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+)
+
+const (
+	healthPath    = "/healthz"
+	readinessPath = "/readyz"
+
+	statusAvailable   = "ok"
+	statusUnavailable = "unavailable"
+)
+
+// maxDatabaseAge is the age beyond which the database is considered too
+// stale to serve, where zero disables the check
+var maxDatabaseAge time.Duration
+
+// Health is the resource model of the health and readiness probes
+type Health struct {
+	Status string  `json:"status"`
+	Checks []Check `json:"checks,omitempty"`
+}
+
+// Check is the outcome of a single readiness condition
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// healthz reports that the process is alive and able to serve requests
+func healthz(w http.ResponseWriter, r *http.Request) {
+	if !allowProbe(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, &Health{Status: statusAvailable})
+}
+
+// readyz reports whether the database is loaded and recent enough to serve
+func readyz(w http.ResponseWriter, r *http.Request) {
+	if !allowProbe(w, r) {
+		return
+	}
+
+	health := &Health{Status: statusAvailable}
+	check := func(name string, ok bool, detail string) {
+		c := Check{Name: name, Status: statusAvailable, Detail: detail}
+		if !ok {
+			c.Status = statusUnavailable
+			health.Status = statusUnavailable
+		}
+		health.Checks = append(health.Checks, c)
+	}
+
+	check("database_loaded", m2v.IsLoaded(), "")
+	if maxDatabaseAge > 0 {
+		age := time.Since(m2v.Updated()).Round(time.Second)
+		check("database_age", age <= maxDatabaseAge, age.String()+" old, limited to "+maxDatabaseAge.String())
+	}
+
+	status := http.StatusOK
+	if health.Status != statusAvailable {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, health)
+}
+
+func allowProbe(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+	writeProblem(w, newProblem(r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "probes only support GET and HEAD"))
+	return false
+}
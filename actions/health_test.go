@@ -0,0 +1,52 @@
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	defer func(age time.Duration) {
+		maxDatabaseAge = age
+	}(maxDatabaseAge)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		maxAge time.Duration
+		code   int
+		status string
+	}{
+		{"Liveness", http.MethodGet, healthPath, 0, http.StatusOK, statusAvailable},
+		{"Readiness", http.MethodGet, readinessPath, 0, http.StatusOK, statusAvailable},
+		{"Readiness Within Age", http.MethodGet, readinessPath, 100 * 365 * 24 * time.Hour, http.StatusOK, statusAvailable},
+		{"Readiness Stale", http.MethodGet, readinessPath, time.Hour, http.StatusServiceUnavailable, statusUnavailable},
+		{"Head", http.MethodHead, readinessPath, 0, http.StatusOK, ""},
+		{"Unsupported Method", http.MethodPost, healthPath, 0, http.StatusMethodNotAllowed, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxDatabaseAge = tt.maxAge
+			w := httptest.NewRecorder()
+			newRouter().ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.code {
+				t.Errorf("received unexpected status code: %v; expected %v", w.Code, tt.code)
+			}
+			if tt.status == "" {
+				return
+			}
+
+			var health Health
+			if err := json.Unmarshal(w.Body.Bytes(), &health); err != nil {
+				t.Fatal("failed to decode response: ", err)
+			}
+			if health.Status != tt.status {
+				t.Errorf("received unexpected status: %+v; expected %v", health, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package actions
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+)
+
+const (
+	// maxUpdates bounds the updates retained for review
+	maxUpdates = 20
+	// maxChanges bounds the changes listed by each update
+	maxChanges = 100
+)
+
+var (
+	// swapMu serializes replacements of the default database along with
+	// their persistence, so that the database file holds the database being
+	// served and each update is recorded against the database it replaced
+	swapMu sync.Mutex
+
+	historyMu sync.Mutex
+	history   []*Update
+)
+
+// Update is the v1 resource model of a replacement of the vendor database
+type Update struct {
+	Time     time.Time `json:"time"`
+	Reason   string    `json:"reason"`
+	Previous string    `json:"previous_version"`
+	Version  string    `json:"version"`
+	Entries  int       `json:"entries"`
+	Added    int       `json:"added"`
+	Removed  int       `json:"removed"`
+	Changed  int       `json:"changed"`
+	// Changes lists the first of the changed prefixes, where the previous
+	// vendor of added prefixes and the vendor of removed ones are empty
+	Changes   []Change `json:"changes"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+// Change is the v1 resource model of a changed prefix
+type Change struct {
+	Prefix   string `json:"prefix"`
+	Previous string `json:"previous,omitempty"`
+	Vendor   string `json:"vendor,omitempty"`
+}
+
+// swapDatabase replaces the default database, recording the differences
+// from the one it replaced. The caller must hold swapMu
+func swapDatabase(db *m2v.Database, reason string) *Update {
+	previous := m2v.Default()
+	update := diff(previous, db)
+	update.Time = time.Now().UTC()
+	update.Reason = reason
+	m2v.SetDefault(db)
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	history = append([]*Update{update}, history...)
+	if len(history) > maxUpdates {
+		history = history[:maxUpdates]
+	}
+	return update
+}
+
+// updates returns the recorded updates, most recent first
+func updates() []*Update {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	return append(make([]*Update, 0, len(history)), history...)
+}
+
+// diff compares the entries of two databases, which are ordered by prefix,
+// ignoring the surrounding whitespace that some listings pad vendors with
+func diff(from, to *m2v.Database) *Update {
+	update := &Update{Previous: from.Version(), Version: to.Version(), Entries: to.Len(), Changes: make([]Change, 0)}
+	record := func(c Change) {
+		if len(update.Changes) < maxChanges {
+			update.Changes = append(update.Changes, c)
+			return
+		}
+		update.Truncated = true
+	}
+
+	old, cur := from.Entries(), to.Entries()
+	for i, j := 0, 0; i < len(old) || j < len(cur); {
+		switch {
+		case j == len(cur) || (i < len(old) && old[i].Prefix < cur[j].Prefix):
+			update.Removed++
+			record(Change{Prefix: old[i].Prefix, Previous: old[i].Vendor})
+			i++
+		case i == len(old) || cur[j].Prefix < old[i].Prefix:
+			update.Added++
+			record(Change{Prefix: cur[j].Prefix, Vendor: cur[j].Vendor})
+			j++
+		default:
+			if strings.TrimSpace(old[i].Vendor) != strings.TrimSpace(cur[j].Vendor) {
+				update.Changed++
+				record(Change{Prefix: cur[j].Prefix, Previous: old[i].Vendor, Vendor: cur[j].Vendor})
+			}
+			i++
+			j++
+		}
+	}
+	return update
+}
@@ -0,0 +1,204 @@
+package actions
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	m2v "github.com/n3integration/mac2vendor"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	sysfsNet      = "/sys/class/net"
+	allInterfaces bool
+	netInterfaces = net.Interfaces
+	driverAddress = ethtoolPermAddr
+
+	// addrAssignTypes describes the values of addr_assign_type, see linux/netdevice.h
+	addrAssignTypes = map[string]string{
+		"0": "permanent",
+		"1": "random",
+		"2": "stolen",
+		"3": "set",
+	}
+)
+
+func init() {
+	register(cli.Command{
+		Name:    "interfaces",
+		Aliases: []string{"ifaces"},
+		Action:  interfacesAction,
+		Usage:   "list the local network interfaces and resolve their vendors",
+		Flags: append([]cli.Flag{
+			cli.StringFlag{
+				Destination: &sysfsNet,
+				Name:        "sysfs",
+				Value:       sysfsNet,
+				Usage:       "the path of the sysfs network class directory",
+			},
+			cli.BoolFlag{
+				Destination: &allInterfaces,
+				Name:        "all, a",
+				Usage:       "include interfaces without a hardware address",
+			},
+		}, outputFlags...),
+	})
+}
+
+// localInterface is a network interface of this host
+type localInterface struct {
+	Name            string `json:"name" yaml:"name"`
+	Index           int    `json:"index" yaml:"index"`
+	MAC             string `json:"mac,omitempty" yaml:"mac,omitempty"`
+	PermanentMAC    string `json:"permanent_mac,omitempty" yaml:"permanent_mac,omitempty"`
+	AddrAssign      string `json:"addr_assign,omitempty" yaml:"addr_assign,omitempty"`
+	Vendor          string `json:"vendor" yaml:"vendor"`
+	PermanentVendor string `json:"permanent_vendor,omitempty" yaml:"permanent_vendor,omitempty"`
+	Virtual         bool   `json:"virtual" yaml:"virtual"`
+	Hypervisor      string `json:"hypervisor,omitempty" yaml:"hypervisor,omitempty"`
+	Flags           string `json:"flags" yaml:"flags"`
+}
+
+func (i localInterface) header() []string {
+	return []string{"name", "index", "mac", "permanent_mac", "addr_assign", "vendor", "permanent_vendor", "virtual", "hypervisor", "flags"}
+}
+
+func (i localInterface) row() []string {
+	return []string{
+		i.Name,
+		fmt.Sprint(i.Index),
+		i.MAC,
+		i.PermanentMAC,
+		i.AddrAssign,
+		i.Vendor,
+		i.PermanentVendor,
+		fmt.Sprint(i.Virtual),
+		i.Hypervisor,
+		i.Flags,
+	}
+}
+
+func (i localInterface) text() string {
+	kind := "physical"
+	if i.Virtual {
+		kind = "virtual"
+	}
+	if i.Hypervisor != "" {
+		kind += " (" + i.Hypervisor + ")"
+	}
+
+	s := fmt.Sprintf("%d: %s %s %s %s", i.Index, i.Name, orNone(i.MAC), kind, orNone(i.Vendor))
+	if i.PermanentMAC != "" && i.PermanentMAC != i.MAC {
+		s += fmt.Sprintf(" permaddr %s %s", i.PermanentMAC, orNone(i.PermanentVendor))
+	}
+	return s
+}
+
+func interfacesAction(_ *cli.Context) error {
+	ifaces, err := readInterfaces()
+	if err != nil {
+		return err
+	}
+
+	p, err := newPrinter(stdout)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range ifaces {
+		if !allInterfaces && i.MAC == "" {
+			continue
+		}
+		if err := p.Print(i); err != nil {
+			return err
+		}
+	}
+	return p.Close()
+}
+
+// readInterfaces enumerates the local interfaces, supplementing them with
+// the details published in sysfs, and classifies their addresses
+func readInterfaces() ([]localInterface, error) {
+	ifaces, err := netInterfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]localInterface, 0, len(ifaces))
+	for _, iface := range ifaces {
+		li := localInterface{
+			Name:  iface.Name,
+			Index: iface.Index,
+			Flags: iface.Flags.String(),
+		}
+		if len(iface.HardwareAddr) > 0 {
+			li.MAC = iface.HardwareAddr.String()
+		}
+
+		dir := filepath.Join(sysfsNet, iface.Name)
+		if _, err := os.Stat(dir); err == nil {
+			li.AddrAssign = addrAssignTypes[readSysfs(dir, "addr_assign_type")]
+			li.Virtual = !exists(filepath.Join(dir, "device"))
+			li.PermanentMAC = permanentAddress(dir, iface.Name)
+		}
+
+		if err := li.classify(); err != nil {
+			return nil, err
+		}
+		result = append(result, li)
+	}
+	return result, nil
+}
+
+// classify resolves the vendors and virtualization platform of the interface
+func (i *localInterface) classify() error {
+	if i.MAC == "" {
+		return nil
+	}
+
+	var err error
+	if i.Vendor, err = m2v.Lookup(i.MAC); err != nil {
+		return err
+	}
+	if i.Hypervisor, err = m2v.Hypervisor(i.MAC); err != nil {
+		return err
+	}
+	i.Virtual = i.Virtual || i.Hypervisor != ""
+
+	if i.PermanentMAC != "" && i.PermanentMAC != i.MAC {
+		if i.PermanentVendor, err = m2v.Lookup(i.PermanentMAC); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// permanentAddress returns the burned-in address of the interface, which
+// differs from its current address for bonded slaves and spoofed interfaces
+func permanentAddress(dir, name string) string {
+	addr := readSysfs(dir, "bonding_slave/perm_hwaddr")
+	if addr == "" {
+		addr = driverAddress(name)
+	}
+	if hw, err := net.ParseMAC(addr); err == nil && !isZero(hw) {
+		return hw.String()
+	}
+	return ""
+}
+
+func readSysfs(dir, attr string) string {
+	b, err := ioutil.ReadFile(filepath.Join(dir, attr))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,52 @@
+package actions
+
+import (
+	"net"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// see linux/sockios.h and linux/ethtool.h
+	siocEthtool      = 0x8946
+	ethtoolGPermAddr = 0x20
+	maxAddrLen       = 32
+)
+
+type ethtoolPermAddrReq struct {
+	cmd  uint32
+	size uint32
+	data [maxAddrLen]byte
+}
+
+type ifreq struct {
+	name [syscall.IFNAMSIZ]byte
+	data unsafe.Pointer
+	_    [16]byte
+}
+
+// ethtoolPermAddr queries the driver of the named interface for its
+// permanent hardware address
+func ethtoolPermAddr(name string) string {
+	if len(name) >= syscall.IFNAMSIZ {
+		return ""
+	}
+
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return ""
+	}
+	defer syscall.Close(fd)
+
+	addr := ethtoolPermAddrReq{cmd: ethtoolGPermAddr, size: maxAddrLen}
+	req := ifreq{data: unsafe.Pointer(&addr)}
+	copy(req.name[:], name)
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), siocEthtool, uintptr(unsafe.Pointer(&req))); errno != 0 {
+		return ""
+	}
+	if addr.size == 0 || addr.size > maxAddrLen {
+		return ""
+	}
+	return net.HardwareAddr(addr.data[:addr.size]).String()
+}
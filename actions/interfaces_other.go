@@ -0,0 +1,8 @@
+//go:build !linux
+// +build !linux
+
+package actions
+
+func ethtoolPermAddr(_ string) string {
+	return ""
+}
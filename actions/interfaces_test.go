@@ -0,0 +1,69 @@
+package actions
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestInterfaces(t *testing.T) {
+	sysfsNet = "testdata/sys"
+	netInterfaces = func() ([]net.Interface, error) {
+		return []net.Interface{
+			{Index: 1, Name: "lo", Flags: net.FlagUp | net.FlagLoopback},
+			{Index: 2, Name: "eth0", HardwareAddr: mustParseMAC(t, "84:38:35:77:aa:52"), Flags: net.FlagUp},
+			{Index: 3, Name: "eth1", HardwareAddr: mustParseMAC(t, "84:38:35:77:aa:52"), Flags: net.FlagUp},
+			{Index: 4, Name: "veth0", HardwareAddr: mustParseMAC(t, "02:42:ac:11:00:02"), Flags: net.FlagUp},
+			{Index: 5, Name: "ens3", HardwareAddr: mustParseMAC(t, "52:54:00:12:34:56"), Flags: net.FlagUp},
+		}, nil
+	}
+	driverAddress = func(string) string {
+		return ""
+	}
+	defer func() {
+		netInterfaces, driverAddress, allInterfaces, stdout = net.Interfaces, ethtoolPermAddr, false, os.Stdout
+	}()
+
+	ifaces, err := readInterfaces()
+	if err != nil {
+		t.Fatal("failed to read interfaces: ", err)
+	}
+
+	expected := []localInterface{
+		{Name: "lo", Index: 1, Flags: "up|loopback"},
+		{Name: "eth0", Index: 2, MAC: "84:38:35:77:aa:52", AddrAssign: "permanent", Vendor: "Apple, Inc.", Flags: "up"},
+		{Name: "eth1", Index: 3, MAC: "84:38:35:77:aa:52", PermanentMAC: "00:00:0c:00:00:02", AddrAssign: "stolen",
+			Vendor: "Apple, Inc.", PermanentVendor: "Cisco Systems, Inc", Flags: "up"},
+		{Name: "veth0", Index: 4, MAC: "02:42:ac:11:00:02", AddrAssign: "random", Virtual: true, Hypervisor: "Docker", Flags: "up"},
+		{Name: "ens3", Index: 5, MAC: "52:54:00:12:34:56", Virtual: true, Hypervisor: "QEMU/KVM", Flags: "up"},
+	}
+	if len(ifaces) != len(expected) {
+		t.Fatalf("received %d interfaces; expected %d", len(ifaces), len(expected))
+	}
+	for i := range expected {
+		if ifaces[i] != expected[i] {
+			t.Errorf("unexpected interface %d: %+v; expected %+v", i, ifaces[i], expected[i])
+		}
+	}
+
+	t.Run("Action", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		stdout = out
+		if err := interfacesAction(nil); err != nil {
+			t.Fatal("failed to list interfaces: ", err)
+		}
+		if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 4 {
+			t.Errorf("expected interfaces without an address to be skipped: %q", lines)
+		}
+	})
+}
+
+func mustParseMAC(t *testing.T, s string) net.HardwareAddr {
+	hw, err := net.ParseMAC(s)
+	if err != nil {
+		t.Fatal("failed to parse mac: ", err)
+	}
+	return hw
+}
@@ -0,0 +1,374 @@
+package actions
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	leaseAuto    = "auto"
+	leaseDnsmasq = "dnsmasq"
+	leaseDhcpd   = "dhcpd"
+	leaseKea     = "kea"
+
+	dhcpdTimeLayout = "2006/01/02 15:04:05"
+	keaStateDefault = "0"
+)
+
+var (
+	leaseType    string
+	unknownOnly  bool
+	leaseParsers = map[string]func(io.Reader) ([]lease, error){
+		leaseDnsmasq: parseDnsmasqLeases,
+		leaseDhcpd:   parseDhcpdLeases,
+		leaseKea:     parseKeaLeases,
+	}
+)
+
+func init() {
+	register(cli.Command{
+		Name:      "leases",
+		Action:    leasesAction,
+		Usage:     "resolve the vendors of the clients in dhcp lease files",
+		ArgsUsage: "FILE...",
+		Flags: append([]cli.Flag{
+			cli.StringFlag{
+				Destination: &leaseType,
+				Name:        "type",
+				Value:       leaseAuto,
+				Usage:       "the lease file format (auto, dnsmasq, dhcpd or kea)",
+			},
+			cli.BoolFlag{
+				Destination: &unknownOnly,
+				Name:        "unknown",
+				Usage:       "only list leases whose vendor is unknown",
+			},
+		}, outputFlags...),
+	})
+}
+
+// lease is an address handed to a client by a dhcp server
+type lease struct {
+	Hostname string    `json:"hostname" yaml:"hostname"`
+	IP       string    `json:"ip" yaml:"ip"`
+	MAC      string    `json:"mac" yaml:"mac"`
+	Vendor   string    `json:"vendor" yaml:"vendor"`
+	Expires  time.Time `json:"expires" yaml:"expires"`
+}
+
+func (l lease) header() []string {
+	return []string{"hostname", "ip", "mac", "vendor", "expires"}
+}
+
+func (l lease) row() []string {
+	return []string{l.Hostname, l.IP, l.MAC, l.Vendor, formatTime(l.Expires)}
+}
+
+func (l lease) text() string {
+	return fmt.Sprintf("%s %s %s %s", orNone(l.Hostname), l.IP, l.MAC, orNone(l.Vendor))
+}
+
+func leasesAction(c *cli.Context) error {
+	if len(c.Args()) == 0 {
+		return errors.New("at least one lease file is required")
+	}
+
+	p, err := newPrinter(stdout)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range c.Args() {
+		leases, err := readLeaseFile(name, leaseType)
+		if err != nil {
+			return errors.Wrap(err, name)
+		}
+
+		for _, l := range leases {
+			if unknownOnly && l.Vendor != "" {
+				continue
+			}
+			if err := p.Print(l); err != nil {
+				return err
+			}
+		}
+	}
+	return p.Close()
+}
+
+// readLeaseFile parses the named lease file, which is read from stdin when
+// the name is "-", and resolves the vendor of every lease
+func readLeaseFile(name, kind string) ([]lease, error) {
+	r := stdin
+	if name != stdinName {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open "+name)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	br := bufio.NewReader(r)
+	if kind == leaseAuto {
+		kind = detectLeaseType(br)
+	}
+
+	parse, ok := leaseParsers[kind]
+	if !ok {
+		return nil, errors.Errorf("unsupported lease file format: %s", kind)
+	}
+
+	leases, err := parse(br)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range leases {
+		vnd, err := m2v.Lookup(leases[i].MAC)
+		if err != nil {
+			return nil, err
+		}
+		leases[i].Vendor = vnd
+	}
+	return leases, nil
+}
+
+// detectLeaseType identifies the format of the lease file from its first
+// significant line
+func detectLeaseType(r *bufio.Reader) string {
+	head, _ := r.Peek(r.Size())
+	for _, line := range strings.Split(string(head), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "address,"):
+			return leaseKea
+		case strings.HasPrefix(line, "lease ") || strings.HasSuffix(line, ";"):
+			return leaseDhcpd
+		default:
+			return leaseDnsmasq
+		}
+	}
+	return leaseDnsmasq
+}
+
+// parseDnsmasqLeases parses dnsmasq.leases entries of the form
+// "expiry mac ip hostname client-id", skipping ipv6 leases
+func parseDnsmasqLeases(r io.Reader) ([]lease, error) {
+	leases := make([]lease, 0)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] == "duid" {
+			continue
+		}
+		if len(fields) < 4 {
+			return nil, errors.Errorf("malformed dnsmasq lease on line %d: %q", line, scanner.Text())
+		}
+
+		hw, err := net.ParseMAC(fields[1])
+		if err != nil {
+			// ipv6 leases record the iaid in place of the hardware address
+			continue
+		}
+
+		l := lease{IP: fields[2], MAC: hw.String()}
+		if fields[3] != "*" {
+			l.Hostname = fields[3]
+		}
+		if expiry, err := strconv.ParseInt(fields[0], 10, 64); err != nil {
+			return nil, errors.Wrapf(err, "malformed dnsmasq lease expiry on line %d", line)
+		} else if expiry > 0 {
+			l.Expires = time.Unix(expiry, 0).UTC()
+		}
+		leases = append(leases, l)
+	}
+	return leases, errors.Wrap(scanner.Err(), "failed to read dnsmasq leases")
+}
+
+// parseDhcpdLeases parses the active lease declarations of an isc
+// dhcpd.leases file, where later declarations for an address supersede
+// earlier ones
+func parseDhcpdLeases(r io.Reader) ([]lease, error) {
+	var current *lease
+	var state string
+	index := make(map[string]int)
+	states := make(map[string]string)
+	leases := make([]lease, 0)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		stmt := strings.TrimSpace(scanner.Text())
+		if stmt == "" || strings.HasPrefix(stmt, "#") {
+			continue
+		}
+
+		if current == nil {
+			if fields := strings.Fields(stmt); len(fields) == 3 && fields[0] == "lease" && fields[2] == "{" {
+				current, state = &lease{IP: fields[1]}, ""
+			}
+			continue
+		}
+
+		if stmt == "}" {
+			if current.MAC != "" {
+				if i, ok := index[current.IP]; ok {
+					leases[i] = *current
+				} else {
+					index[current.IP] = len(leases)
+					leases = append(leases, *current)
+				}
+				states[current.IP] = state
+			}
+			current = nil
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimSuffix(stmt, ";"))
+		switch {
+		case len(fields) == 3 && fields[0] == "hardware" && fields[1] == "ethernet":
+			hw, err := net.ParseMAC(fields[2])
+			if err != nil {
+				return nil, errors.Wrapf(err, "malformed dhcpd hardware address on line %d", line)
+			}
+			current.MAC = hw.String()
+		case len(fields) == 3 && fields[0] == "binding" && fields[1] == "state":
+			state = fields[2]
+		case len(fields) >= 2 && fields[0] == "client-hostname":
+			current.Hostname = parseDhcpdString(strings.TrimSpace(strings.TrimPrefix(strings.TrimSuffix(stmt, ";"), fields[0])))
+		case len(fields) >= 2 && fields[0] == "ends":
+			expires, err := parseDhcpdTime(fields[1:])
+			if err != nil {
+				return nil, errors.Wrapf(err, "malformed dhcpd lease end on line %d", line)
+			}
+			current.Expires = expires
+		}
+	}
+
+	if current != nil {
+		return nil, errors.New("unterminated dhcpd lease declaration")
+	}
+
+	// free, expired, released, abandoned and backup leases are not held by
+	// any client, while older servers do not record the binding state
+	active := leases[:0]
+	for _, l := range leases {
+		if state := states[l.IP]; state == "" || state == "active" {
+			active = append(active, l)
+		}
+	}
+	return active, errors.Wrap(scanner.Err(), "failed to read dhcpd leases")
+}
+
+// parseDhcpdString returns the value of a quoted dhcpd string, which escapes
+// non-printable characters as octal sequences
+func parseDhcpdString(s string) string {
+	if value, err := strconv.Unquote(s); err == nil {
+		return value
+	}
+	return strings.Trim(s, `"`)
+}
+
+// parseDhcpdTime parses the "never", "W YYYY/MM/DD HH:MM:SS" and
+// "epoch N" forms of dhcpd lease times
+func parseDhcpdTime(fields []string) (time.Time, error) {
+	switch {
+	case fields[0] == "never":
+		return time.Time{}, nil
+	case fields[0] == "epoch" && len(fields) >= 2:
+		sec, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(sec, 0).UTC(), nil
+	case len(fields) >= 3:
+		return time.Parse(dhcpdTimeLayout, fields[1]+" "+fields[2])
+	default:
+		return time.Time{}, errors.Errorf("unsupported time %q", strings.Join(fields, " "))
+	}
+}
+
+// parseKeaLeases parses the rows of a kea memfile lease csv file, where later
+// rows for an address supersede earlier ones and only leases in the default
+// state are held by clients
+func parseKeaLeases(r io.Reader) ([]lease, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read kea lease header")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range []string{"address", "hwaddr"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.Errorf("kea lease file is missing the %s column", name)
+		}
+	}
+
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	index := make(map[string]int)
+	states := make(map[string]string)
+	leases := make([]lease, 0)
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "failed to read kea leases")
+		}
+
+		hw, err := net.ParseMAC(field(row, "hwaddr"))
+		if err != nil {
+			continue
+		}
+
+		l := lease{
+			Hostname: strings.TrimSuffix(field(row, "hostname"), "."),
+			IP:       field(row, "address"),
+			MAC:      hw.String(),
+		}
+		if expire, err := strconv.ParseInt(field(row, "expire"), 10, 64); err == nil && expire > 0 {
+			l.Expires = time.Unix(expire, 0).UTC()
+		}
+		if i, ok := index[l.IP]; ok {
+			leases[i] = l
+		} else {
+			index[l.IP] = len(leases)
+			leases = append(leases, l)
+		}
+		states[l.IP] = field(row, "state")
+	}
+
+	// declined and expired-reclaimed leases are not held by any client
+	active := leases[:0]
+	for _, l := range leases {
+		if state := states[l.IP]; state == "" || state == keaStateDefault {
+			active = append(active, l)
+		}
+	}
+	return active, nil
+}
@@ -0,0 +1,120 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLeases(t *testing.T) {
+	expires := time.Date(2019, 3, 2, 13, 0, 0, 0, time.UTC)
+	expected := []lease{
+		{Hostname: "macbook", IP: "192.168.1.10", MAC: "84:38:35:77:aa:52", Vendor: "Apple, Inc.", Expires: expires},
+		{IP: "192.168.1.11", MAC: "02:00:00:00:00:01"},
+	}
+
+	tests := []struct {
+		name string
+		file string
+		kind string
+	}{
+		{"Dnsmasq", "testdata/leases/dnsmasq.leases", leaseDnsmasq},
+		{"Dhcpd", "testdata/leases/dhcpd.leases", leaseDhcpd},
+		{"Kea", "testdata/leases/kea-leases4.csv", leaseKea},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, kind := range []string{tt.kind, leaseAuto} {
+				leases, err := readLeaseFile(tt.file, kind)
+				if err != nil {
+					t.Fatal("failed to read leases: ", err)
+				}
+				if len(leases) != len(expected) {
+					t.Fatalf("received %d leases; expected %d: %+v", len(leases), len(expected), leases)
+				}
+				for i := range expected {
+					if leases[i] != expected[i] {
+						t.Errorf("unexpected lease %d: %+v; expected %+v", i, leases[i], expected[i])
+					}
+				}
+			}
+		})
+	}
+
+	t.Run("Malformed", func(t *testing.T) {
+		if _, err := parseDnsmasqLeases(strings.NewReader("1551531600 84:38:35:77:aa:52\n")); err == nil {
+			t.Error("expected a malformed dnsmasq lease to fail")
+		}
+		if _, err := parseDhcpdLeases(strings.NewReader("lease 192.168.1.10 {\n")); err == nil {
+			t.Error("expected an unterminated dhcpd lease to fail")
+		}
+		if _, err := parseKeaLeases(strings.NewReader("ip,mac\n")); err == nil {
+			t.Error("expected a kea file without an address column to fail")
+		}
+	})
+
+	t.Run("State", func(t *testing.T) {
+		dhcpd := `lease 192.168.1.10 {
+  binding state active;
+  hardware ethernet 84:38:35:77:aa:52;
+  client-hostname "living room tv";
+}
+lease 192.168.1.11 {
+  binding state free;
+  hardware ethernet 02:00:00:00:00:01;
+}
+lease 192.168.1.12 {
+  binding state active;
+  hardware ethernet 02:00:00:00:00:02;
+}
+lease 192.168.1.12 {
+  binding state expired;
+  next binding state free;
+  hardware ethernet 02:00:00:00:00:02;
+}
+`
+		leases, err := parseDhcpdLeases(strings.NewReader(dhcpd))
+		if err != nil {
+			t.Fatal("failed to parse dhcpd leases: ", err)
+		}
+		if len(leases) != 1 || leases[0].IP != "192.168.1.10" || leases[0].Hostname != "living room tv" {
+			t.Errorf("expected only the active lease to be listed, received %+v", leases)
+		}
+
+		kea := "address,hwaddr,expire,hostname,state\n" +
+			"192.168.1.10,84:38:35:77:aa:52,0,macbook.,0\n" +
+			"192.168.1.11,02:00:00:00:00:01,0,,1\n" +
+			"192.168.1.12,02:00:00:00:00:02,0,,0\n" +
+			"192.168.1.12,02:00:00:00:00:02,0,,2\n"
+		leases, err = parseKeaLeases(strings.NewReader(kea))
+		if err != nil {
+			t.Fatal("failed to parse kea leases: ", err)
+		}
+		if len(leases) != 1 || leases[0].IP != "192.168.1.10" {
+			t.Errorf("expected only the active lease to be listed, received %+v", leases)
+		}
+	})
+
+	t.Run("Action", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		unknownOnly, leaseType, output, stdout = true, leaseAuto, outputJSON, out
+		defer func() {
+			unknownOnly, output, stdout = false, outputText, os.Stdout
+		}()
+
+		if err := leasesAction(newContext(t, "testdata/leases/dhcpd.leases", "testdata/leases/kea-leases4.csv")); err != nil {
+			t.Fatal("failed to list leases: ", err)
+		}
+
+		var leases []lease
+		if err := json.Unmarshal(out.Bytes(), &leases); err != nil {
+			t.Fatal("failed to decode output: ", err)
+		}
+		if len(leases) != 2 || leases[0].MAC != "02:00:00:00:00:01" {
+			t.Errorf("expected only unknown vendors to be listed, received %+v", leases)
+		}
+	})
+}
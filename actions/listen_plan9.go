@@ -0,0 +1,7 @@
+package actions
+
+// isRefused reports no refusals, as unix domain sockets are not supported on
+// this platform
+func isRefused(error) bool {
+	return false
+}
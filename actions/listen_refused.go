@@ -0,0 +1,21 @@
+//go:build !plan9
+// +build !plan9
+
+package actions
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// isRefused returns whether a connection failed because nothing listens on
+// the address
+func isRefused(err error) bool {
+	if op, ok := err.(*net.OpError); ok {
+		if sys, ok := op.Err.(*os.SyscallError); ok {
+			return sys.Err == syscall.ECONNREFUSED
+		}
+	}
+	return false
+}
@@ -1,52 +1,291 @@
 package actions
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"log"
+	"net"
+	"os"
+	"runtime"
+	"strings"
 
 	m2v "github.com/n3integration/mac2vendor"
+	"github.com/pkg/errors"
 	"gopkg.in/urfave/cli.v1"
 )
 
+const (
+	stdinName = "-"
+
+	statusOK      = "ok"
+	statusUnknown = "unknown"
+	statusInvalid = "invalid"
+
+	// quietFormat prints the vendor of each address, or why it was not
+	// resolved, on a line of its own
+	quietFormat = "{{if .Error}}{{.Error}}{{else if .Vendor}}{{.Vendor}}{{else}}" + statusUnknown + "{{end}}"
+)
+
 var (
-	mac   string
-	quiet bool
+	mac     string
+	file    string
+	quiet   bool
+	workers int
+
+	stdin  io.Reader = os.Stdin
+	stdout io.Writer = os.Stdout
 )
 
 func init() {
 	log.SetFlags(log.LstdFlags)
 	register(cli.Command{
-		Name:    "lookup",
-		Aliases: []string{"resolve"},
-		Action:  lookupAction,
-		Usage:   "lookup a mac address and resolve its vendor",
-		Flags: []cli.Flag{
+		Name:      "lookup",
+		Aliases:   []string{"resolve"},
+		Action:    lookupAction,
+		Usage:     "lookup one or more mac addresses and resolve their vendors",
+		ArgsUsage: "[mac...]",
+		Description: "Addresses are read from the --mac flag, positional arguments and the --file flag.\n" +
+			"   When none are provided, or when an argument is \"-\", newline-delimited\n" +
+			"   addresses are read from stdin. Results are written in input order.",
+		Flags: append([]cli.Flag{
 			cli.StringFlag{
 				Destination: &mac,
 				Name:        "mac",
 				Usage:       "the mac address to resolve",
 			},
+			cli.StringFlag{
+				Destination: &file,
+				Name:        "file",
+				Usage:       "a file of newline-delimited mac addresses to resolve",
+			},
 			cli.BoolFlag{
 				Destination: &quiet,
 				Name:        "quiet",
 				Usage:       "whether or not to run in quiet mode",
 			},
-		},
+			cli.IntFlag{
+				Destination: &workers,
+				Name:        "workers",
+				Value:       runtime.NumCPU(),
+				Usage:       "the number of addresses to resolve concurrently",
+			},
+		}, outputFlags...),
 	})
 }
 
-func lookupAction(_ *cli.Context) error {
-	vnd, err := m2v.Lookup(mac)
+// result is the outcome of resolving a single address
+type result struct {
+	Input  string `json:"input" yaml:"input"`
+	MAC    string `json:"mac,omitempty" yaml:"mac,omitempty"`
+	Vendor string `json:"vendor" yaml:"vendor"`
+	Status string `json:"status" yaml:"status"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func newResult(input string) result {
+	res := result{Input: input, Status: statusInvalid}
+	hw, err := net.ParseMAC(input)
 	if err != nil {
-		return err
+		res.Error = err.Error()
+		return res
 	}
 
-	if quiet {
-		fmt.Println(vnd)
+	res.MAC = hw.String()
+	vnd, err := m2v.Lookup(hw)
+	switch {
+	case err != nil:
+		res.Error = err.Error()
+	case vnd == "":
+		res.Status = statusUnknown
+	default:
+		res.Vendor = vnd
+		res.Status = statusOK
+	}
+	return res
+}
+
+func (r result) header() []string {
+	return []string{"input", "mac", "vendor", "status", "error"}
+}
+
+func (r result) row() []string {
+	return []string{r.Input, r.MAC, r.Vendor, r.Status, r.Error}
+}
+
+func (r result) text() string {
+	if r.Error != "" {
+		return fmt.Sprintf("   MAC: %s\n Error: %s", r.Input, r.Error)
+	}
+	if r.Status == statusUnknown {
+		return fmt.Sprintf("   MAC: %s\nVendor: %s", r.Input, statusUnknown)
+	}
+	return fmt.Sprintf("   MAC: %s\nVendor: %s", r.Input, r.Vendor)
+}
+
+func lookupAction(c *cli.Context) error {
+	var p printer
+	var err error
+	if quiet && outputTemplate == "" {
+		p, err = newTemplatePrinter(stdout, quietFormat)
 	} else {
-		fmt.Printf("   MAC: %s\n", mac)
-		fmt.Printf("Vendor: %s\n", vnd)
+		p, err = newPrinter(stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	// stops reading and resolving inputs when the results are not printed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inputs := make(chan string)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(inputs)
+		errc <- readInputs(ctx, c.Args(), inputs)
+	}()
+
+	var total, unknown, invalid int
+	for res := range resolve(ctx, inputs, workers) {
+		total++
+		switch res.Status {
+		case statusInvalid:
+			invalid++
+			log.Printf("%s: %s\n", res.Input, res.Error)
+		case statusUnknown:
+			unknown++
+		}
+		if err := p.Print(res); err != nil {
+			return err
+		}
 	}
 
+	if err := p.Close(); err != nil {
+		return err
+	}
+	if err := <-errc; err != nil {
+		return err
+	}
+	if unknown+invalid > 0 {
+		return cli.NewExitError(fmt.Sprintf("%d of %d addresses not resolved (%d unknown, %d invalid)",
+			unknown+invalid, total, unknown, invalid), 1)
+	}
 	return nil
 }
+
+// readInputs sends every address provided on the command line, in the input
+// file or on stdin to out until the context is done
+func readInputs(ctx context.Context, args cli.Args, out chan<- string) error {
+	if mac == "" && file == "" && len(args) == 0 {
+		return scanInputs(ctx, stdin, out)
+	}
+
+	if mac != "" {
+		if err := send(ctx, out, mac); err != nil {
+			return err
+		}
+	}
+
+	for _, arg := range args {
+		if arg == stdinName {
+			if err := scanInputs(ctx, stdin, out); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := send(ctx, out, arg); err != nil {
+			return err
+		}
+	}
+
+	switch file {
+	case "":
+		return nil
+	case stdinName:
+		return scanInputs(ctx, stdin, out)
+	default:
+		f, err := os.Open(file)
+		if err != nil {
+			return errors.Wrap(err, "failed to open "+file)
+		}
+		defer f.Close()
+		return scanInputs(ctx, f, out)
+	}
+}
+
+// scanInputs sends each non-blank, non-comment line of r to out
+func scanInputs(ctx context.Context, r io.Reader, out chan<- string) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := send(ctx, out, line); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(scanner.Err(), "failed to read input")
+}
+
+// send sends the input to out unless the context is done first
+func send(ctx context.Context, out chan<- string, input string) error {
+	select {
+	case out <- input:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resolve looks up each address received from inputs using n concurrent
+// workers and emits the results in input order until the context is done
+func resolve(ctx context.Context, inputs <-chan string, n int) <-chan result {
+	if n < 1 {
+		n = 1
+	}
+
+	type job struct {
+		input string
+		out   chan<- result
+	}
+
+	jobs := make(chan job)
+	pending := make(chan chan result, n)
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		for input := range inputs {
+			out := make(chan result, 1)
+			select {
+			case pending <- out:
+			case <-ctx.Done():
+				return
+			}
+			jobs <- job{input: input, out: out}
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		go func() {
+			for j := range jobs {
+				j.out <- newResult(j.input)
+			}
+		}()
+	}
+
+	results := make(chan result)
+	go func() {
+		defer close(results)
+		for out := range pending {
+			select {
+			case results <- <-out:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return results
+}
@@ -1,22 +1,116 @@
 package actions
 
 import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
+
+	"gopkg.in/urfave/cli.v1"
 )
 
+func newContext(t *testing.T, args ...string) *cli.Context {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := set.Parse(args); err != nil {
+		t.Fatal("failed to parse arguments: ", err)
+	}
+	return cli.NewContext(nil, set, nil)
+}
+
 func TestLookup(t *testing.T) {
 	mac = "84:38:35:77:aa:52"
+	defer func(n int) {
+		mac, file, quiet, workers = "", "", false, n
+		stdin, stdout = os.Stdin, os.Stdout
+	}(workers)
 
 	t.Run("Default", func(t *testing.T) {
-		if err := lookupAction(nil); err != nil {
+		if err := lookupAction(newContext(t)); err != nil {
 			t.Error("failed to lookup mac: ", err)
 		}
 	})
 
 	t.Run("Quiet", func(t *testing.T) {
 		quiet = true
-		if err := lookupAction(nil); err != nil {
+		if err := lookupAction(newContext(t)); err != nil {
 			t.Error("failed to lookup mac: ", err)
 		}
 	})
+
+	t.Run("Bulk", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		mac, quiet, workers = "", true, 4
+		stdin = strings.NewReader("00:00:0c:00:00:01\n\n# comment\n00-00-0e-00-00-01\n")
+		stdout = out
+
+		f, err := ioutil.TempFile("", "macs")
+		if err != nil {
+			t.Fatal("failed to create input file: ", err)
+		}
+		defer os.Remove(f.Name())
+		f.WriteString("3c:d9:2b:00:00:01\n")
+		f.Close()
+		file = f.Name()
+
+		if err := lookupAction(newContext(t, "84:38:35:77:aa:52", "-")); err != nil {
+			t.Fatal("failed to lookup macs: ", err)
+		}
+
+		expected := []string{
+			"Apple, Inc.",
+			"Cisco Systems, Inc",
+			"FUJITSU LIMITED",
+			"Hewlett Packard",
+		}
+		if actual := strings.Split(strings.TrimSpace(out.String()), "\n"); strings.Join(actual, "|") != strings.Join(expected, "|") {
+			t.Errorf("unexpected results: %q; expected %q", actual, expected)
+		}
+	})
+
+	t.Run("Unresolved", func(t *testing.T) {
+		mac, file, quiet = "", "", true
+		stdin = strings.NewReader("84:38:35:77:aa:52\n02:00:00:00:00:01\nnot-a-mac\n")
+		out := new(bytes.Buffer)
+		stdout = out
+
+		err := lookupAction(newContext(t))
+		if err == nil {
+			t.Fatal("expected an error for the invalid address")
+		}
+		if exit, ok := err.(cli.ExitCoder); !ok || exit.ExitCode() == 0 {
+			t.Errorf("expected a non-zero exit status, received %v", err)
+		}
+
+		expected := []string{"Apple, Inc.", statusUnknown, "address not-a-mac: invalid MAC address"}
+		if actual := strings.Split(strings.TrimSpace(out.String()), "\n"); strings.Join(actual, "|") != strings.Join(expected, "|") {
+			t.Errorf("unexpected results: %q; expected %q", actual, expected)
+		}
+	})
+	t.Run("Print Error", func(t *testing.T) {
+		mac, file, quiet, workers = "", "", true, 4
+		stdin = strings.NewReader(strings.Repeat("84:38:35:77:aa:52\n", 100))
+		stdout = failingWriter{}
+
+		running := runtime.NumGoroutine()
+		if err := lookupAction(newContext(t)); err == nil {
+			t.Fatal("expected the print error to be returned")
+		}
+		for deadline := time.Now().Add(5 * time.Second); runtime.NumGoroutine() > running; {
+			if time.Now().After(deadline) {
+				t.Fatalf("expected the lookup to stop resolving, %d goroutines are running", runtime.NumGoroutine()-running)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, os.ErrClosed
 }
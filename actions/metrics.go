@@ -0,0 +1,158 @@
+package actions
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	metricsNamespace = "mac2vendor"
+	metricsPath      = "/metrics"
+
+	lookupHit     = "hit"
+	lookupMiss    = "miss"
+	lookupInvalid = "invalid"
+)
+
+var (
+	metrics = prometheus.NewRegistry()
+
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "The number of http requests by route, method and status code.",
+	}, []string{"route", "method", "code"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "The latency of http requests by route.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"route"})
+
+	lookupsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "lookups_total",
+		Help:      "The number of address lookups by result (hit, miss or invalid).",
+	}, []string{"result"})
+
+	refreshesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "database",
+		Name:      "refreshes_total",
+		Help:      "The number of background database refreshes by result (success or failure).",
+	}, []string{"result"})
+
+	lastRefresh = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "database",
+		Name:      "last_refresh_timestamp_seconds",
+		Help:      "The unix time of the last successful background database refresh.",
+	})
+)
+
+func init() {
+	metrics.MustRegister(
+		requestsTotal,
+		requestDuration,
+		lookupsTotal,
+		refreshesTotal,
+		lastRefresh,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "http",
+			Name:      "streams",
+			Help:      "The number of streaming lookups in progress.",
+		}, func() float64 {
+			return float64(atomic.LoadInt64(&openStreams))
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "database",
+			Name:      "entries",
+			Help:      "The number of vendor prefixes in the database.",
+		}, func() float64 {
+			return float64(m2v.Len())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "database",
+			Name:      "updated_timestamp_seconds",
+			Help:      "The unix time at which the database was generated.",
+		}, func() float64 {
+			return float64(m2v.Updated().Unix())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: "database",
+			Name:      "age_seconds",
+			Help:      "The number of seconds since the database was generated.",
+		}, func() float64 {
+			return time.Since(m2v.Updated()).Seconds()
+		}),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	for _, result := range []string{lookupHit, lookupMiss, lookupInvalid} {
+		lookupsTotal.WithLabelValues(result)
+	}
+	for _, result := range []string{refreshSuccess, refreshFailure} {
+		refreshesTotal.WithLabelValues(result)
+	}
+}
+
+// metricsHandler exposes the service metrics in the prometheus format
+func metricsHandler() http.Handler {
+	return promhttp.HandlerFor(metrics, promhttp.HandlerOpts{})
+}
+
+// countLookup records the result of an address lookup
+func countLookup(vendor string, err error) {
+	switch {
+	case err != nil:
+		lookupsTotal.WithLabelValues(lookupInvalid).Inc()
+	case vendor == "":
+		lookupsTotal.WithLabelValues(lookupMiss).Inc()
+	default:
+		lookupsTotal.WithLabelValues(lookupHit).Inc()
+	}
+}
+
+// instrument is middleware recording the count and latency of requests by
+// the route pattern of the router that handles them
+func instrument(router *http.ServeMux, next http.Handler) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := router.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		wi := &interceptor{delegate: w}
+		start := time.Now()
+		defer func() {
+			requestDuration.WithLabelValues(route).Observe(time.Since(start).Seconds())
+			requestsTotal.WithLabelValues(route, method(r), strconv.Itoa(wi.StatusCode())).Inc()
+		}()
+		next.ServeHTTP(wi, r)
+	})
+}
+
+// method bounds the cardinality of the method label to the standard methods
+func method(r *http.Request) string {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions:
+		return r.Method
+	default:
+		return "OTHER"
+	}
+}
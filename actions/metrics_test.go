@@ -0,0 +1,47 @@
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	m2v "github.com/n3integration/mac2vendor"
+)
+
+func TestMetrics(t *testing.T) {
+	router := newRouter()
+	handler := instrument(router, router)
+	for _, path := range []string{"/v1/lookup/84:38:35:77:aa:52", "/v1/lookup/52:54:00:12:34:56", "/v1/lookup/not-a-mac"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, metricsPath, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("received unexpected status code: %v", w.Code)
+	}
+
+	body := w.Body.String()
+	for _, expected := range []string{
+		`mac2vendor_http_requests_total{code="200",method="GET",route="/v1/lookup/"}`,
+		`mac2vendor_http_requests_total{code="400",method="GET",route="/v1/lookup/"} 1`,
+		`mac2vendor_http_request_duration_seconds_bucket{route="/v1/lookup/",le="+Inf"}`,
+		`mac2vendor_lookups_total{result="hit"}`,
+		`mac2vendor_lookups_total{result="miss"}`,
+		`mac2vendor_lookups_total{result="invalid"}`,
+		`mac2vendor_database_updated_timestamp_seconds 1.5514848e+09`,
+		`mac2vendor_database_age_seconds`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected metrics to contain %s", expected)
+		}
+	}
+	// gauges are written in the shortest float representation of their value
+	entries := fmt.Sprintf("\nmac2vendor_database_entries %s\n", strconv.FormatFloat(float64(m2v.Len()), 'g', -1, 64))
+	if !strings.Contains(body, entries) {
+		t.Errorf("expected metrics to contain %q", strings.TrimSpace(entries))
+	}
+}
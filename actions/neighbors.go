@@ -0,0 +1,239 @@
+package actions
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	m2v "github.com/n3integration/mac2vendor"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const (
+	sourceAll     = "all"
+	sourceARP     = "arp"
+	sourceNetlink = "netlink"
+
+	// arp flags, see linux/if_arp.h
+	atfComplete  = 0x02
+	atfPermanent = 0x04
+)
+
+var (
+	arpTable       = "/proc/net/arp"
+	neighborSource string
+	neighborIface  string
+	errNoNetlink   = errors.New("netlink is not supported on this platform")
+)
+
+func init() {
+	register(cli.Command{
+		Name:    "neighbors",
+		Aliases: []string{"neigh", "arp"},
+		Action:  neighborsAction,
+		Usage:   "list the arp/neighbour table and resolve the vendor of each entry",
+		Flags: append([]cli.Flag{
+			cli.StringFlag{
+				Destination: &arpTable,
+				Name:        "proc",
+				Value:       arpTable,
+				Usage:       "the path of the proc arp table",
+			},
+			cli.StringFlag{
+				Destination: &neighborSource,
+				Name:        "source",
+				Value:       sourceAll,
+				Usage:       "the neighbour table source (all, arp or netlink)",
+			},
+			cli.StringFlag{
+				Destination: &neighborIface,
+				Name:        "interface, i",
+				Usage:       "only list neighbours on the named interface",
+			},
+		}, outputFlags...),
+	})
+}
+
+// neighbor is an entry in the arp/neighbour table
+type neighbor struct {
+	IP        string `json:"ip" yaml:"ip"`
+	Interface string `json:"interface" yaml:"interface"`
+	MAC       string `json:"mac,omitempty" yaml:"mac,omitempty"`
+	State     string `json:"state" yaml:"state"`
+	Vendor    string `json:"vendor" yaml:"vendor"`
+}
+
+func (n neighbor) header() []string {
+	return []string{"ip", "interface", "mac", "state", "vendor"}
+}
+
+func (n neighbor) row() []string {
+	return []string{n.IP, n.Interface, n.MAC, n.State, n.Vendor}
+}
+
+func (n neighbor) text() string {
+	return fmt.Sprintf("%s dev %s lladdr %s %s %s", n.IP, n.Interface, orNone(n.MAC), n.State, orNone(n.Vendor))
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func neighborsAction(_ *cli.Context) error {
+	neighbors, err := readNeighbors(neighborSource)
+	if err != nil {
+		return err
+	}
+
+	p, err := newPrinter(stdout)
+	if err != nil {
+		return err
+	}
+
+	for _, n := range neighbors {
+		if neighborIface != "" && n.Interface != neighborIface {
+			continue
+		}
+		if err := p.Print(n); err != nil {
+			return err
+		}
+	}
+	return p.Close()
+}
+
+// readNeighbors reads the neighbour table from the requested source and
+// resolves the vendor of every entry
+func readNeighbors(source string) ([]neighbor, error) {
+	var neighbors []neighbor
+	switch source {
+	case sourceARP:
+		arp, err := readARPFile(arpTable)
+		if err != nil {
+			return nil, err
+		}
+		neighbors = arp
+	case sourceNetlink:
+		nl, err := readNetlinkNeighbors()
+		if err != nil {
+			return nil, err
+		}
+		neighbors = nl
+	case sourceAll:
+		// netlink is not available on every platform and may be refused,
+		// such as within containers, in which case only the arp table is read
+		nl, nlErr := readNetlinkNeighbors()
+		arp, err := readARPFile(arpTable)
+		if err != nil && (nlErr != nil || !os.IsNotExist(errors.Cause(err))) {
+			return nil, err
+		}
+		neighbors = mergeNeighbors(nl, arp)
+	default:
+		return nil, errors.Errorf("unsupported neighbour source: %s", source)
+	}
+
+	for i := range neighbors {
+		if neighbors[i].MAC == "" {
+			continue
+		}
+		vnd, err := m2v.Lookup(neighbors[i].MAC)
+		if err != nil {
+			return nil, err
+		}
+		neighbors[i].Vendor = vnd
+	}
+
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		if neighbors[i].Interface != neighbors[j].Interface {
+			return neighbors[i].Interface < neighbors[j].Interface
+		}
+		return bytes.Compare(net.ParseIP(neighbors[i].IP).To16(), net.ParseIP(neighbors[j].IP).To16()) < 0
+	})
+	return neighbors, nil
+}
+
+// mergeNeighbors combines the entries of both tables, preferring the first
+// when an address is listed in both
+func mergeNeighbors(preferred, other []neighbor) []neighbor {
+	seen := make(map[string]bool)
+	merged := make([]neighbor, 0, len(preferred)+len(other))
+	for _, tbl := range [][]neighbor{preferred, other} {
+		for _, n := range tbl {
+			key := n.Interface + "/" + n.IP
+			if !seen[key] {
+				seen[key] = true
+				merged = append(merged, n)
+			}
+		}
+	}
+	return merged
+}
+
+// readARPFile parses the neighbour entries of a proc arp table
+func readARPFile(path string) ([]neighbor, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open "+path)
+	}
+	defer f.Close()
+	return parseARP(f)
+}
+
+func parseARP(r io.Reader) ([]neighbor, error) {
+	neighbors := make([]neighbor, 0)
+	scanner := bufio.NewScanner(r)
+	for line := 0; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if line == 0 || len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 6 {
+			return nil, errors.Errorf("malformed arp entry on line %d: %q", line+1, scanner.Text())
+		}
+
+		flags, err := strconv.ParseUint(strings.TrimPrefix(fields[2], "0x"), 16, 32)
+		if err != nil {
+			return nil, errors.Wrapf(err, "malformed arp flags on line %d", line+1)
+		}
+
+		n := neighbor{
+			IP:        fields[0],
+			Interface: fields[5],
+			State:     arpState(flags),
+		}
+		if hw, err := net.ParseMAC(fields[3]); err == nil && !isZero(hw) {
+			n.MAC = hw.String()
+		}
+		neighbors = append(neighbors, n)
+	}
+	return neighbors, errors.Wrap(scanner.Err(), "failed to read arp table")
+}
+
+func isZero(hw net.HardwareAddr) bool {
+	for _, b := range hw {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func arpState(flags uint64) string {
+	switch {
+	case flags&atfPermanent != 0:
+		return "permanent"
+	case flags&atfComplete != 0:
+		return "complete"
+	default:
+		return "incomplete"
+	}
+}
@@ -0,0 +1,110 @@
+package actions
+
+import (
+	"encoding/binary"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// neighbour attributes and states, see linux/neighbour.h
+	ndaDst    = 1
+	ndaLLAddr = 2
+	nudNone   = 0x00
+	nudNoARP  = 0x40
+
+	sizeofNdMsg = 12
+)
+
+var nudStates = []struct {
+	state uint16
+	name  string
+}{
+	{0x80, "permanent"},
+	{0x02, "reachable"},
+	{0x04, "stale"},
+	{0x08, "delay"},
+	{0x10, "probe"},
+	{0x20, "failed"},
+	{0x01, "incomplete"},
+}
+
+// readNetlinkNeighbors dumps the kernel neighbour table over netlink,
+// skipping the entries `ip neigh` hides by default
+func readNetlinkNeighbors() ([]neighbor, error) {
+	tab, err := syscall.NetlinkRIB(syscall.RTM_GETNEIGH, syscall.AF_UNSPEC)
+	if err != nil {
+		return nil, errors.Wrap(os.NewSyscallError("netlinkrib", err), "failed to dump neighbour table")
+	}
+
+	msgs, err := syscall.ParseNetlinkMessage(tab)
+	if err != nil {
+		return nil, errors.Wrap(os.NewSyscallError("parsenetlinkmessage", err), "failed to parse neighbour table")
+	}
+
+	neighbors := make([]neighbor, 0)
+	for _, m := range msgs {
+		if m.Header.Type == syscall.NLMSG_DONE {
+			break
+		}
+		if m.Header.Type != syscall.RTM_NEWNEIGH || len(m.Data) < sizeofNdMsg {
+			continue
+		}
+
+		index := int32(binary.NativeEndian.Uint32(m.Data[4:8]))
+		state := binary.NativeEndian.Uint16(m.Data[8:10])
+		if state == nudNone || state&nudNoARP != 0 {
+			continue
+		}
+
+		n := neighbor{
+			Interface: interfaceName(int(index)),
+			State:     nudState(state),
+		}
+		for attrs := m.Data[sizeofNdMsg:]; len(attrs) >= syscall.SizeofRtAttr; {
+			l := int(binary.NativeEndian.Uint16(attrs[0:2]))
+			if l < syscall.SizeofRtAttr || l > len(attrs) {
+				break
+			}
+			value := attrs[syscall.SizeofRtAttr:l]
+			switch binary.NativeEndian.Uint16(attrs[2:4]) {
+			case ndaDst:
+				n.IP = net.IP(value).String()
+			case ndaLLAddr:
+				if hw := net.HardwareAddr(value); len(hw) > 0 && !isZero(hw) {
+					n.MAC = hw.String()
+				}
+			}
+			if l = rtaAlign(l); l > len(attrs) {
+				break
+			}
+			attrs = attrs[l:]
+		}
+		neighbors = append(neighbors, n)
+	}
+	return neighbors, nil
+}
+
+func rtaAlign(l int) int {
+	return (l + syscall.RTA_ALIGNTO - 1) &^ (syscall.RTA_ALIGNTO - 1)
+}
+
+func interfaceName(index int) string {
+	if iface, err := net.InterfaceByIndex(index); err == nil {
+		return iface.Name
+	}
+	return strconv.Itoa(index)
+}
+
+func nudState(state uint16) string {
+	for _, s := range nudStates {
+		if state&s.state != 0 {
+			return s.name
+		}
+	}
+	return "0x" + strconv.FormatUint(uint64(state), 16)
+}
@@ -0,0 +1,91 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNeighbors(t *testing.T) {
+	arpTable = "testdata/arp"
+	defer func(table, source string) {
+		arpTable, neighborSource, neighborIface = table, source, ""
+		output, stdout = outputText, os.Stdout
+	}(arpTable, neighborSource)
+
+	t.Run("ParseARP", func(t *testing.T) {
+		neighbors, err := readNeighbors(sourceARP)
+		if err != nil {
+			t.Fatal("failed to read arp table: ", err)
+		}
+
+		expected := []neighbor{
+			{IP: "192.168.1.1", Interface: "eth0", MAC: "84:38:35:77:aa:52", State: "complete", Vendor: "Apple, Inc."},
+			{IP: "192.168.1.10", Interface: "eth0", MAC: "00:00:0c:12:34:56", State: "complete", Vendor: "Cisco Systems, Inc"},
+			{IP: "10.0.0.1", Interface: "wlan0", MAC: "02:00:00:00:00:01", State: "permanent"},
+			{IP: "10.0.0.7", Interface: "wlan0", State: "incomplete"},
+		}
+		if len(neighbors) != len(expected) {
+			t.Fatalf("received %d neighbours; expected %d", len(neighbors), len(expected))
+		}
+		for i := range expected {
+			if neighbors[i] != expected[i] {
+				t.Errorf("unexpected neighbour %d: %+v; expected %+v", i, neighbors[i], expected[i])
+			}
+		}
+	})
+
+	t.Run("All", func(t *testing.T) {
+		neighbors, err := readNeighbors(sourceAll)
+		if err != nil {
+			t.Fatal("failed to read neighbour tables: ", err)
+		}
+
+		found := 0
+		for _, n := range neighbors {
+			if n.IP == "10.0.0.7" || n.IP == "192.168.1.10" {
+				found++
+			}
+		}
+		if found != 2 {
+			t.Errorf("expected the arp table entries to be listed, received %+v", neighbors)
+		}
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		if _, err := parseARP(strings.NewReader("header\n192.168.1.1 0x1 0x2\n")); err == nil {
+			t.Error("expected malformed arp entry to fail")
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		nl := []neighbor{{IP: "192.168.1.1", Interface: "eth0", State: "stale"}}
+		arp := []neighbor{{IP: "192.168.1.1", Interface: "eth0", State: "complete"}, {IP: "192.168.1.2", Interface: "eth0"}}
+		if merged := mergeNeighbors(nl, arp); len(merged) != 2 || merged[0].State != "stale" {
+			t.Errorf("unexpected merged neighbours: %+v", merged)
+		}
+	})
+
+	t.Run("Action", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		neighborSource, neighborIface, output, stdout = sourceARP, "eth0", outputJSON, out
+		if err := neighborsAction(nil); err != nil {
+			t.Fatal("failed to list neighbours: ", err)
+		}
+
+		var neighbors []neighbor
+		if err := json.Unmarshal(out.Bytes(), &neighbors); err != nil {
+			t.Fatal("failed to decode output: ", err)
+		} else if len(neighbors) != 2 {
+			t.Errorf("expected only eth0 neighbours to be listed, received %+v", neighbors)
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		if _, err := readNeighbors("snmp"); err == nil {
+			t.Error("expected an unsupported source to fail")
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package actions
+
+import (
+	_ "embed"
+	"log"
+	"net/http"
+)
+
+const openapiPath = "/openapi.json"
+
+// openapiSpec is the OpenAPI 3 description of the web service
+//
+//go:embed openapi.json
+var openapiSpec []byte
+
+// openapi publishes the OpenAPI description of the web service
+func openapi(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+		writeProblem(w, newProblem(r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "the api description only supports GET"))
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(openapiSpec); err != nil {
+		log.Println("failed to write response: ", err)
+	}
+}
@@ -0,0 +1,173 @@
+package actions
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	m2v "github.com/n3integration/mac2vendor"
+)
+
+func init() {
+	openapi3filter.RegisterBodyDecoder(contentTypeNDJSON, ndjsonBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("text/html", openapi3filter.PlainBodyDecoder)
+}
+
+// ndjsonBodyDecoder decodes each line of a newline delimited body as an element
+// of an array, as documented by the specification
+func ndjsonBodyDecoder(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (interface{}, error) {
+	values := make([]interface{}, 0)
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var v interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, scanner.Err()
+}
+
+func TestOpenAPI(t *testing.T) {
+	ctx := context.Background()
+	router := newRouter()
+
+	w := httptest.NewRecorder()
+	guard(router).ServeHTTP(w, httptest.NewRequest(http.MethodGet, openapiPath, nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != contentTypeJSON {
+		t.Fatalf("received unexpected response: %v %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(w.Body.Bytes())
+	if err != nil {
+		t.Fatal("failed to load specification: ", err)
+	}
+	if err := doc.Validate(ctx); err != nil {
+		t.Fatal("invalid specification: ", err)
+	}
+	routes, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatal("failed to route specification: ", err)
+	}
+
+	// the admin api is enabled with an overrides file and an upstream listing
+	dir, err := ioutil.TempDir("", "mac2vnd")
+	if err != nil {
+		t.Fatal("failed to create directory: ", err)
+	}
+	defer os.RemoveAll(dir)
+	var listing strings.Builder
+	for _, e := range m2v.Embedded().Entries() {
+		fmt.Fprintf(&listing, "%s\t%s\n", e.Prefix, e.Vendor)
+	}
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(listing.String()))
+	}))
+	defer upstream.Close()
+
+	defer func(keys map[[sha256.Size]byte]bool, db, o, src string, sink logSink, f *refresher) {
+		adminKeys, databaseFile, overridesFile, source, auditLog, refreshes = keys, db, o, src, sink, f
+		overrides.Store(m2v.NewOverrides())
+		m2v.SetDefault(m2v.Embedded())
+	}(adminKeys, databaseFile, overridesFile, source, auditLog, refreshes)
+	adminKeys = map[[sha256.Size]byte]bool{sha256.Sum256([]byte("admin")): true}
+	databaseFile, overridesFile = "", filepath.Join(dir, "overrides")
+	source, auditLog, refreshes = upstream.URL, &writerSink{w: ioutil.Discard}, nil
+
+	tests := []struct {
+		method      string
+		path        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{http.MethodGet, "/v1/lookup/84:38:35:77:aa:52", "", "", http.StatusOK},
+		{http.MethodGet, "/v1/lookup/52-54-00-12-34-56", "", "", http.StatusOK},
+		{http.MethodGet, "/v1/lookup/not-a-mac", "", "", http.StatusBadRequest},
+		{http.MethodPost, "/v1/lookup", contentTypeJSON, `["84:38:35:77:aa:52","not-a-mac"]`, http.StatusOK},
+		{http.MethodPost, "/v1/lookup", contentTypeNDJSON, "\"84:38:35:77:aa:52\"\n\"00:00:0c:00:00:01\"\n", http.StatusOK},
+		{http.MethodPost, "/v1/lookup", contentTypeJSON, `{}`, http.StatusBadRequest},
+		{http.MethodGet, "/v1/search?q=apple&limit=5", "", "", http.StatusOK},
+		{http.MethodGet, "/v1/search?q=", "", "", http.StatusBadRequest},
+		{http.MethodGet, "/v1/info", "", "", http.StatusOK},
+		{http.MethodGet, "/healthz", "", "", http.StatusOK},
+		{http.MethodGet, "/readyz", "", "", http.StatusOK},
+		{http.MethodGet, "/metrics", "", "", http.StatusOK},
+		{http.MethodGet, "/openapi.json", "", "", http.StatusOK},
+		{http.MethodGet, "/ui/", "", "", http.StatusOK},
+		{http.MethodGet, "/84:38:35:77:aa:52", "", "", http.StatusOK},
+		{http.MethodGet, "/not-a-mac", "", "", http.StatusBadRequest},
+		{http.MethodGet, "/v1/stream", "", "", http.StatusUpgradeRequired},
+		{http.MethodPut, "/admin/overrides", contentTypeJSON, `[{"key":"84:38:35","vendor":"Lab Devices"}]`, http.StatusOK},
+		{http.MethodPut, "/admin/overrides", contentTypeText, "84:38:35 Lab Devices\n02:00:00:00:00:01 Test Device\n", http.StatusOK},
+		{http.MethodPut, "/admin/overrides", contentTypeJSON, `[{"key":"not-a-mac","vendor":"Lab"}]`, http.StatusBadRequest},
+		{http.MethodGet, "/admin/overrides", "", "", http.StatusOK},
+		{http.MethodPut, "/admin/overrides/84:38:35:77:aa:52", contentTypeJSON, `{"vendor":"Lab Device"}`, http.StatusOK},
+		{http.MethodGet, "/admin/overrides/84:38:35:77:aa:52", "", "", http.StatusOK},
+		{http.MethodDelete, "/admin/overrides/84:38:35:77:aa:52", "", "", http.StatusNoContent},
+		{http.MethodGet, "/admin/overrides/84:38:35:77:aa:52", "", "", http.StatusNotFound},
+		{http.MethodGet, "/admin/overrides/not-a-mac", "", "", http.StatusBadRequest},
+		{http.MethodPost, "/admin/reload", "", "", http.StatusOK},
+		{http.MethodPost, "/admin/update", "", "", http.StatusOK},
+		{http.MethodGet, "/admin/updates", "", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		route, params, err := routes.FindRoute(req)
+		if err != nil {
+			t.Errorf("%s %s is not described: %v", tt.method, tt.path, err)
+			continue
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s %s received unexpected status code: %v", tt.method, tt.path, w.Code)
+			continue
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)),
+			PathParams: params,
+			Route:      route,
+			Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+		}
+		if tt.contentType != "" {
+			input.Request.Header.Set("Content-Type", tt.contentType)
+		}
+		// requests the service rejects need not conform to the specification
+		if err := openapi3filter.ValidateRequest(ctx, input); err != nil && tt.status < http.StatusBadRequest {
+			t.Errorf("%s %s sent an invalid request: %v", tt.method, tt.path, err)
+		}
+
+		err = openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 w.Code,
+			Header:                 w.Header(),
+			Body:                   ioutil.NopCloser(bytes.NewReader(w.Body.Bytes())),
+		})
+		if err != nil {
+			t.Errorf("%s %s received an invalid response: %v", tt.method, tt.path, err)
+		}
+	}
+}
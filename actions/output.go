@@ -0,0 +1,250 @@
+package actions
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	outputText  = "text"
+	outputJSON  = "json"
+	outputJSONL = "jsonl"
+	outputCSV   = "csv"
+	outputTSV   = "tsv"
+	outputYAML  = "yaml"
+	outputTable = "table"
+)
+
+var (
+	output         string
+	outputTemplate string
+)
+
+// outputFlags are shared by every command that writes records
+var outputFlags = []cli.Flag{
+	cli.StringFlag{
+		Destination: &output,
+		Name:        "output, o",
+		Value:       outputText,
+		Usage:       "the output format (text, json, jsonl, csv, tsv, yaml or table)",
+	},
+	cli.StringFlag{
+		Destination: &outputTemplate,
+		Name:        "format",
+		Usage:       "a go template applied to each record, which takes precedence over --output",
+	},
+}
+
+// record is a single row of command output
+type record interface {
+	// header returns the column names of the record
+	header() []string
+	// row returns the column values of the record
+	row() []string
+	// text returns the human readable form of the record
+	text() string
+}
+
+// printer writes records in a specific output format
+type printer interface {
+	Print(r record) error
+	Close() error
+}
+
+// newPrinter returns a printer for the format requested on the command line
+func newPrinter(w io.Writer) (printer, error) {
+	if outputTemplate != "" {
+		return newTemplatePrinter(w, outputTemplate)
+	}
+
+	switch strings.ToLower(output) {
+	case "", outputText:
+		return &textPrinter{w: w}, nil
+	case outputJSON:
+		return &jsonPrinter{w: w}, nil
+	case outputJSONL:
+		return &jsonlPrinter{enc: json.NewEncoder(w)}, nil
+	case outputCSV:
+		return &csvPrinter{w: csv.NewWriter(w)}, nil
+	case outputTSV:
+		writer := csv.NewWriter(w)
+		writer.Comma = '\t'
+		return &csvPrinter{w: writer}, nil
+	case outputYAML:
+		return &yamlPrinter{w: w}, nil
+	case outputTable:
+		return &tablePrinter{w: tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)}, nil
+	default:
+		return nil, errors.Errorf("unsupported output format: %s", output)
+	}
+}
+
+// textPrinter writes the human readable form of records, separating those
+// spanning several lines with a blank line
+type textPrinter struct {
+	w     io.Writer
+	count int
+}
+
+func (p *textPrinter) Print(r record) error {
+	text := r.text()
+	if p.count > 0 && strings.Contains(text, "\n") {
+		fmt.Fprintln(p.w)
+	}
+	p.count++
+	_, err := fmt.Fprintln(p.w, text)
+	return err
+}
+
+func (p *textPrinter) Close() error {
+	return nil
+}
+
+type templatePrinter struct {
+	w   io.Writer
+	tpl *template.Template
+}
+
+func newTemplatePrinter(w io.Writer, text string) (*templatePrinter, error) {
+	tpl, err := template.New("format").Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse format")
+	}
+	return &templatePrinter{w: w, tpl: tpl}, nil
+}
+
+func (p *templatePrinter) Print(r record) error {
+	if err := p.tpl.Execute(p.w, r); err != nil {
+		return errors.Wrap(err, "failed to execute format")
+	}
+	_, err := fmt.Fprintln(p.w)
+	return err
+}
+
+func (p *templatePrinter) Close() error {
+	return nil
+}
+
+// jsonPrinter streams records as the elements of a single json array
+type jsonPrinter struct {
+	w     io.Writer
+	count int
+}
+
+func (p *jsonPrinter) Print(r record) error {
+	b, err := json.MarshalIndent(r, "  ", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode record")
+	}
+
+	delim := ",\n  "
+	if p.count == 0 {
+		delim = "[\n  "
+	}
+	p.count++
+	_, err = fmt.Fprintf(p.w, "%s%s", delim, b)
+	return err
+}
+
+func (p *jsonPrinter) Close() error {
+	if p.count == 0 {
+		_, err := fmt.Fprintln(p.w, "[]")
+		return err
+	}
+	_, err := fmt.Fprintln(p.w, "\n]")
+	return err
+}
+
+type jsonlPrinter struct {
+	enc *json.Encoder
+}
+
+func (p *jsonlPrinter) Print(r record) error {
+	return errors.Wrap(p.enc.Encode(r), "failed to encode record")
+}
+
+func (p *jsonlPrinter) Close() error {
+	return nil
+}
+
+type csvPrinter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (p *csvPrinter) Print(r record) error {
+	if !p.header {
+		p.header = true
+		if err := p.w.Write(r.header()); err != nil {
+			return err
+		}
+	}
+	if err := p.w.Write(r.row()); err != nil {
+		return err
+	}
+	p.w.Flush()
+	return p.w.Error()
+}
+
+func (p *csvPrinter) Close() error {
+	p.w.Flush()
+	return p.w.Error()
+}
+
+// yamlPrinter streams records as the items of a single yaml sequence
+type yamlPrinter struct {
+	w io.Writer
+}
+
+func (p *yamlPrinter) Print(r record) error {
+	b, err := yaml.Marshal(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode record")
+	}
+
+	lines := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	for i, line := range lines {
+		prefix := "  "
+		if i == 0 {
+			prefix = "- "
+		}
+		if _, err := fmt.Fprintf(p.w, "%s%s\n", prefix, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *yamlPrinter) Close() error {
+	return nil
+}
+
+// tablePrinter aligns records into columns, which are written on Close
+type tablePrinter struct {
+	w      *tabwriter.Writer
+	header bool
+}
+
+func (p *tablePrinter) Print(r record) error {
+	if !p.header {
+		p.header = true
+		if _, err := fmt.Fprintln(p.w, strings.ToUpper(strings.Join(r.header(), "\t"))); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(p.w, strings.Join(r.row(), "\t"))
+	return err
+}
+
+func (p *tablePrinter) Close() error {
+	return p.w.Flush()
+}
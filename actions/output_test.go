@@ -0,0 +1,136 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestPrinter(t *testing.T) {
+	records := []result{
+		newResult("84:38:35:77:aa:52"),
+		newResult("02:00:00:00:00:01"),
+		newResult("not-a-mac"),
+	}
+	defer func() {
+		output, outputTemplate = outputText, ""
+	}()
+
+	print := func(t *testing.T) string {
+		out := new(bytes.Buffer)
+		p, err := newPrinter(out)
+		if err != nil {
+			t.Fatal("failed to create printer: ", err)
+		}
+		for _, r := range records {
+			if err := p.Print(r); err != nil {
+				t.Fatal("failed to print record: ", err)
+			}
+		}
+		if err := p.Close(); err != nil {
+			t.Fatal("failed to close printer: ", err)
+		}
+		return out.String()
+	}
+
+	t.Run("Text", func(t *testing.T) {
+		output = outputText
+		actual := print(t)
+		if !strings.Contains(actual, "Vendor: "+statusUnknown+"\n") || !strings.Contains(actual, " Error: ") {
+			t.Errorf("expected unknown vendors and errors to be reported explicitly, received %q", actual)
+		}
+		if records := strings.Split(strings.TrimSpace(actual), "\n\n"); len(records) != 3 {
+			t.Errorf("expected records to be separated by a blank line, received %q", actual)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		output = outputJSON
+		var actual []result
+		if err := json.Unmarshal([]byte(print(t)), &actual); err != nil {
+			t.Fatal("failed to decode output: ", err)
+		}
+		if len(actual) != len(records) {
+			t.Fatalf("received %d records; expected %d", len(actual), len(records))
+		}
+		for i := range records {
+			if actual[i] != records[i] {
+				t.Errorf("unexpected record %d: %+v; expected %+v", i, actual[i], records[i])
+			}
+		}
+	})
+
+	t.Run("JSONL", func(t *testing.T) {
+		output = outputJSONL
+		lines := strings.Split(strings.TrimSpace(print(t)), "\n")
+		if len(lines) != len(records) {
+			t.Fatalf("received %d lines; expected %d", len(lines), len(records))
+		}
+		var actual result
+		if err := json.Unmarshal([]byte(lines[1]), &actual); err != nil {
+			t.Fatal("failed to decode line: ", err)
+		} else if actual.Status != statusUnknown {
+			t.Errorf("expected unknown vendor to be reported explicitly, received %+v", actual)
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		output = outputCSV
+		rows, err := csv.NewReader(strings.NewReader(print(t))).ReadAll()
+		if err != nil {
+			t.Fatal("failed to decode output: ", err)
+		}
+		if len(rows) != len(records)+1 {
+			t.Fatalf("received %d rows; expected %d", len(rows), len(records)+1)
+		}
+		if rows[3][3] != statusInvalid || rows[3][4] == "" {
+			t.Errorf("expected invalid address to be reported explicitly, received %q", rows[3])
+		}
+	})
+
+	t.Run("TSV", func(t *testing.T) {
+		output = outputTSV
+		lines := strings.Split(strings.TrimSpace(print(t)), "\n")
+		if fields := strings.Split(lines[1], "\t"); len(fields) != 5 || fields[2] != "Apple, Inc." {
+			t.Errorf("unexpected row: %q", fields)
+		}
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		output = outputYAML
+		var actual []result
+		if err := yaml.Unmarshal([]byte(print(t)), &actual); err != nil {
+			t.Fatal("failed to decode output: ", err)
+		}
+		if len(actual) != len(records) || actual[0] != records[0] {
+			t.Errorf("unexpected records: %+v", actual)
+		}
+	})
+
+	t.Run("Table", func(t *testing.T) {
+		output = outputTable
+		lines := strings.Split(strings.TrimSpace(print(t)), "\n")
+		if len(lines) != len(records)+1 || !strings.HasPrefix(lines[0], "INPUT") {
+			t.Errorf("unexpected table: %q", lines)
+		}
+	})
+
+	t.Run("Template", func(t *testing.T) {
+		outputTemplate = "{{.MAC}}={{.Status}}"
+		if actual := print(t); actual != "84:38:35:77:aa:52=ok\n02:00:00:00:00:01=unknown\n=invalid\n" {
+			t.Errorf("unexpected output: %q", actual)
+		}
+		outputTemplate = ""
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		output = "xml"
+		if _, err := newPrinter(new(bytes.Buffer)); err == nil {
+			t.Error("expected an error for an unsupported format")
+		}
+	})
+}
@@ -0,0 +1,187 @@
+package actions
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var unicastOnly bool
+
+func init() {
+	register(cli.Command{
+		Name:      "pcap",
+		Action:    pcapAction,
+		Usage:     "report the vendors observed in pcap and pcapng capture files",
+		ArgsUsage: "FILE...",
+		Flags: append([]cli.Flag{
+			cli.BoolFlag{
+				Destination: &unicastOnly,
+				Name:        "unicast",
+				Usage:       "exclude broadcast and multicast addresses",
+			},
+		}, outputFlags...),
+	})
+}
+
+// observation summarizes the frames captured to or from an address
+type observation struct {
+	MAC       string    `json:"mac" yaml:"mac"`
+	Vendor    string    `json:"vendor" yaml:"vendor"`
+	Frames    int       `json:"frames" yaml:"frames"`
+	Sent      int       `json:"sent" yaml:"sent"`
+	Received  int       `json:"received" yaml:"received"`
+	FirstSeen time.Time `json:"first_seen" yaml:"first_seen"`
+	LastSeen  time.Time `json:"last_seen" yaml:"last_seen"`
+}
+
+func (o observation) header() []string {
+	return []string{"mac", "vendor", "frames", "sent", "received", "first_seen", "last_seen"}
+}
+
+func (o observation) row() []string {
+	return []string{
+		o.MAC,
+		o.Vendor,
+		fmt.Sprint(o.Frames),
+		fmt.Sprint(o.Sent),
+		fmt.Sprint(o.Received),
+		formatTime(o.FirstSeen),
+		formatTime(o.LastSeen),
+	}
+}
+
+func (o observation) text() string {
+	return fmt.Sprintf("%s %s frames=%d sent=%d received=%d first=%s last=%s",
+		o.MAC, orNone(o.Vendor), o.Frames, o.Sent, o.Received, orNone(formatTime(o.FirstSeen)), orNone(formatTime(o.LastSeen)))
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func pcapAction(c *cli.Context) error {
+	if len(c.Args()) == 0 {
+		return errors.New("at least one capture file is required")
+	}
+
+	observations := make(map[string]*observation)
+	for _, name := range c.Args() {
+		if err := observeFile(name, observations); err != nil {
+			return err
+		}
+	}
+
+	p, err := newPrinter(stdout)
+	if err != nil {
+		return err
+	}
+	for _, o := range sortObservations(observations) {
+		if err := p.Print(o); err != nil {
+			return err
+		}
+	}
+	return p.Close()
+}
+
+// observeFile records the addresses of every frame in the named capture,
+// which is read from stdin when the name is "-"
+func observeFile(name string, observations map[string]*observation) error {
+	r := stdin
+	if name != stdinName {
+		f, err := os.Open(name)
+		if err != nil {
+			return errors.Wrap(err, "failed to open "+name)
+		}
+		defer f.Close()
+		r = f
+	}
+	return errors.Wrap(observe(r, observations), name)
+}
+
+// observe records the addresses of every frame of the capture read from r
+func observe(r io.Reader, observations map[string]*observation) error {
+	frames, err := newFrameReader(r)
+	if err != nil {
+		return err
+	}
+
+	for {
+		f, err := frames.next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		src, dst := frameAddresses(f)
+		if accept(src) {
+			observed(observations, src, f.timestamp).Sent++
+		}
+		if accept(dst) {
+			if bytes.Equal(src, dst) {
+				observations[dst.String()].Received++
+				continue
+			}
+			observed(observations, dst, f.timestamp).Received++
+		}
+	}
+}
+
+// accept is a predicate to determine whether or not the address is reported
+func accept(hw net.HardwareAddr) bool {
+	if hw == nil {
+		return false
+	}
+	return !unicastOnly || hw[0]&0x01 == 0
+}
+
+// observed records a frame for the address, returning its observation
+func observed(observations map[string]*observation, hw net.HardwareAddr, ts time.Time) *observation {
+	mac := hw.String()
+	o, ok := observations[mac]
+	if !ok {
+		o = &observation{MAC: mac}
+		observations[mac] = o
+	}
+
+	o.Frames++
+	if !ts.IsZero() {
+		if o.FirstSeen.IsZero() || ts.Before(o.FirstSeen) {
+			o.FirstSeen = ts
+		}
+		if ts.After(o.LastSeen) {
+			o.LastSeen = ts
+		}
+	}
+	return o
+}
+
+// sortObservations resolves the vendor of every address, ordering them from
+// the most to the least frequently observed
+func sortObservations(observations map[string]*observation) []observation {
+	sorted := make([]observation, 0, len(observations))
+	for _, o := range observations {
+		o.Vendor, _ = m2v.Lookup(o.MAC)
+		sorted = append(sorted, *o)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Frames != sorted[j].Frames {
+			return sorted[i].Frames > sorted[j].Frames
+		}
+		return sorted[i].MAC < sorted[j].MAC
+	})
+	return sorted
+}
@@ -0,0 +1,203 @@
+package actions
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	apple     = []byte{0x84, 0x38, 0x35, 0x77, 0xaa, 0x52}
+	cisco     = []byte{0x00, 0x00, 0x0c, 0x00, 0x00, 0x01}
+	broadcast = []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+)
+
+func ethernetFrame(dst, src []byte) []byte {
+	return append(append(append([]byte{}, dst...), src...), 0x08, 0x00)
+}
+
+func writePcap(order binary.ByteOrder, magic uint32, linkType uint32, frames [][]byte, ts []time.Time) []byte {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, order, []uint32{magic, 0x00040002, 0, 0, 65535, linkType})
+	for i, f := range frames {
+		frac := uint32(ts[i].Nanosecond() / 1000)
+		if magic == pcapMagicNanos {
+			frac = uint32(ts[i].Nanosecond())
+		}
+		binary.Write(buf, order, []uint32{uint32(ts[i].Unix()), frac, uint32(len(f)), uint32(len(f))})
+		buf.Write(f)
+	}
+	return buf.Bytes()
+}
+
+func pcapngBlock(order binary.ByteOrder, blockType uint32, body []byte) []byte {
+	for len(body)%4 != 0 {
+		body = append(body, 0)
+	}
+	length := uint32(12 + len(body))
+	buf := new(bytes.Buffer)
+	binary.Write(buf, order, []uint32{blockType, length})
+	buf.Write(body)
+	binary.Write(buf, order, length)
+	return buf.Bytes()
+}
+
+func writePcapng(order binary.ByteOrder, linkType uint16, tsresol byte, frames [][]byte, ts []uint64) []byte {
+	buf := new(bytes.Buffer)
+
+	shb := new(bytes.Buffer)
+	binary.Write(shb, order, uint32(pcapngByteOrderMagic))
+	binary.Write(shb, order, []uint16{1, 0})
+	binary.Write(shb, order, int64(-1))
+	buf.Write(pcapngBlock(order, pcapngSectionHeader, shb.Bytes()))
+
+	idb := new(bytes.Buffer)
+	binary.Write(idb, order, []uint16{linkType, 0})
+	binary.Write(idb, order, uint32(65535))
+	binary.Write(idb, order, []uint16{pcapngOptionTSResol, 1})
+	idb.Write([]byte{tsresol, 0, 0, 0})
+	binary.Write(idb, order, []uint16{pcapngOptionEnd, 0})
+	buf.Write(pcapngBlock(order, pcapngInterfaceDesc, idb.Bytes()))
+
+	for i, f := range frames {
+		epb := new(bytes.Buffer)
+		binary.Write(epb, order, []uint32{0, uint32(ts[i] >> 32), uint32(ts[i]), uint32(len(f)), uint32(len(f))})
+		epb.Write(f)
+		buf.Write(pcapngBlock(order, pcapngEnhancedPacket, epb.Bytes()))
+	}
+
+	spb := new(bytes.Buffer)
+	binary.Write(spb, order, uint32(len(frames[0])))
+	spb.Write(frames[0])
+	buf.Write(pcapngBlock(order, pcapngSimplePacket, spb.Bytes()))
+	return buf.Bytes()
+}
+
+func TestPcap(t *testing.T) {
+	first := time.Date(2019, 3, 2, 12, 0, 0, 123456000, time.UTC)
+	last := first.Add(time.Minute)
+	frames := [][]byte{
+		ethernetFrame(cisco, apple),
+		ethernetFrame(apple, cisco),
+		ethernetFrame(broadcast, apple),
+	}
+	times := []time.Time{first, first.Add(time.Second), last}
+
+	verify := func(t *testing.T, observations map[string]*observation, frames int, timestamps bool) {
+		sorted := sortObservations(observations)
+		if len(sorted) != 3 {
+			t.Fatalf("received %d observations; expected 3: %+v", len(sorted), sorted)
+		}
+
+		o := sorted[0]
+		if o.MAC != "84:38:35:77:aa:52" || o.Vendor != "Apple, Inc." || o.Frames != frames {
+			t.Errorf("unexpected observation: %+v", o)
+		}
+		if timestamps && (!o.FirstSeen.Equal(first) || !o.LastSeen.Equal(last)) {
+			t.Errorf("unexpected timestamps: %v - %v; expected %v - %v", o.FirstSeen, o.LastSeen, first, last)
+		}
+	}
+
+	t.Run("Pcap", func(t *testing.T) {
+		for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+			for _, magic := range []uint32{pcapMagicMicros, pcapMagicNanos} {
+				observations := make(map[string]*observation)
+				capture := writePcap(order, magic, linkTypeEthernet, frames, times)
+				if err := observe(bytes.NewReader(capture), observations); err != nil {
+					t.Fatal("failed to read capture: ", err)
+				}
+				verify(t, observations, 3, true)
+			}
+		}
+	})
+
+	t.Run("Pcapng", func(t *testing.T) {
+		ts := make([]uint64, len(times))
+		for i := range times {
+			ts[i] = uint64(times[i].UnixNano())
+		}
+
+		for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+			observations := make(map[string]*observation)
+			capture := writePcapng(order, linkTypeEthernet, 9, frames, ts)
+			if err := observe(bytes.NewReader(capture), observations); err != nil {
+				t.Fatal("failed to read capture: ", err)
+			}
+			verify(t, observations, 4, true)
+		}
+	})
+
+	t.Run("Radiotap", func(t *testing.T) {
+		radiotap := []byte{0, 0, 8, 0, 0, 0, 0, 0}
+		// a data frame sent to the distribution system: bssid, source, destination
+		toDS := append(append(append(append(append([]byte{}, radiotap...), 0x08, 0x01, 0, 0), broadcast...), apple...), cisco...)
+		// a data frame from the distribution system: destination, bssid, source
+		fromDS := append(append(append(append(append([]byte{}, radiotap...), 0x08, 0x02, 0, 0), apple...), broadcast...), cisco...)
+		// an acknowledgement only carries the receiver
+		ack := append(append([]byte{}, radiotap...), 0xd4, 0x00, 0, 0, 0x84, 0x38, 0x35, 0x77, 0xaa, 0x52)
+
+		observations := make(map[string]*observation)
+		capture := writePcap(binary.LittleEndian, pcapMagicMicros, linkType80211Radiotap, [][]byte{toDS, fromDS, ack}, times)
+		if err := observe(bytes.NewReader(capture), observations); err != nil {
+			t.Fatal("failed to read capture: ", err)
+		}
+
+		if o := observations["84:38:35:77:aa:52"]; o == nil || o.Sent != 1 || o.Received != 2 {
+			t.Errorf("unexpected observation: %+v", o)
+		}
+		if o := observations["00:00:0c:00:00:01"]; o == nil || o.Sent != 1 || o.Received != 1 {
+			t.Errorf("unexpected observation: %+v", o)
+		}
+		if _, ok := observations["ff:ff:ff:ff:ff:ff"]; ok {
+			t.Error("expected the bssid not to be reported")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if err := observe(strings.NewReader("not a capture"), make(map[string]*observation)); err != errNotCapture {
+			t.Errorf("expected %v, received %v", errNotCapture, err)
+		}
+		capture := writePcap(binary.LittleEndian, pcapMagicMicros, linkTypeEthernet, frames, times)
+		if err := observe(bytes.NewReader(capture[:len(capture)-4]), make(map[string]*observation)); err == nil {
+			t.Error("expected a truncated capture to fail")
+		}
+		for _, tsresol := range []byte{10, 20, 0x80 | 30, 0x80 | 63} {
+			capture := writePcapng(binary.LittleEndian, linkTypeEthernet, tsresol, frames, []uint64{1, 2, 3})
+			if err := observe(bytes.NewReader(capture), make(map[string]*observation)); err == nil {
+				t.Errorf("expected the timestamp resolution %#x to be refused", tsresol)
+			}
+		}
+		capture = writePcapng(binary.LittleEndian, linkTypeEthernet, 0, frames, []uint64{math.MaxUint64, 2, 3})
+		if err := observe(bytes.NewReader(capture), make(map[string]*observation)); err == nil {
+			t.Error("expected a timestamp beyond the range of time to be refused")
+		}
+	})
+
+	t.Run("Action", func(t *testing.T) {
+		f, err := ioutil.TempFile("", "capture")
+		if err != nil {
+			t.Fatal("failed to create capture file: ", err)
+		}
+		defer os.Remove(f.Name())
+		f.Write(writePcap(binary.LittleEndian, pcapMagicMicros, linkTypeEthernet, frames, times))
+		f.Close()
+
+		out := new(bytes.Buffer)
+		unicastOnly, output, stdout = true, outputCSV, out
+		defer func() {
+			unicastOnly, output, stdout = false, outputText, os.Stdout
+		}()
+
+		if err := pcapAction(newContext(t, f.Name())); err != nil {
+			t.Fatal("failed to analyze capture: ", err)
+		}
+		if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 3 {
+			t.Errorf("expected a header and two unicast addresses, received %q", lines)
+		}
+	})
+}
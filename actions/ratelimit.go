@@ -0,0 +1,168 @@
+package actions
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const codeRateLimited = "rate_limited"
+
+var (
+	rateLimit      float64
+	rateBurst      uint = 20
+	trustedProxies string
+
+	// limiter is the rate limiter shared by all requests, or nil when
+	// requests are not limited
+	limiter *rateLimiter
+	// proxies are the networks trusted to report the client address
+	proxies []*net.IPNet
+)
+
+// bucket is the token bucket of a single client
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// rateLimiter is a per-client token bucket rate limiter
+type rateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// newRateLimiter initializes a rate limiter refilling each client's bucket at
+// rate tokens per second up to burst tokens
+func newRateLimiter(rate float64, burst uint) *rateLimiter {
+	if burst == 0 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow takes a token from the client's bucket, returning the duration to
+// wait before retrying when the bucket is empty
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[client] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep periodically discards the buckets of clients that have been idle for
+// long enough to have refilled, bounding the memory held by one-off clients
+func (l *rateLimiter) sweep(now time.Time) {
+	full := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.swept) < full {
+		return
+	}
+	l.swept = now
+	for client, b := range l.buckets {
+		if now.Sub(b.updated) >= full {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// parseProxies parses a comma separated list of addresses and networks
+func parseProxies(list string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0)
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, errors.Errorf("invalid trusted proxy: %s", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid trusted proxy")
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// clientIP returns the address of the client, which is taken from the
+// X-Forwarded-For header when the request was relayed by trusted proxies
+func clientIP(r *http.Request, proxies []*net.IPNet) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !trusted(host, proxies) {
+		return host
+	}
+
+	var hops []string
+	for _, header := range r.Header["X-Forwarded-For"] {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	// the nearest untrusted hop is the client, as anything before it could
+	// have been forged by the client
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		host = hop
+		if !trusted(hop, proxies) {
+			break
+		}
+	}
+	return host
+}
+
+func trusted(host string, proxies []*net.IPNet) bool {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, network := range proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package actions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2019, 3, 2, 12, 0, 0, 0, time.UTC)
+	l := newRateLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("a"); !ok {
+			t.Fatalf("expected request %d to be within the burst", i)
+		}
+	}
+	if ok, wait := l.allow("a"); ok || wait != 500*time.Millisecond {
+		t.Errorf("expected the request to be limited for 500ms, received %v %v", ok, wait)
+	}
+	if ok, _ := l.allow("b"); !ok {
+		t.Error("expected clients to be limited independently")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.allow("a"); !ok {
+		t.Error("expected the bucket to have refilled a token")
+	}
+
+	now = now.Add(time.Minute)
+	l.allow("c")
+	if _, ok := l.buckets["a"]; ok {
+		t.Error("expected idle buckets to be discarded")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	networks, err := parseProxies("10.0.0.0/8, 192.168.1.1")
+	if err != nil {
+		t.Fatal("failed to parse proxies: ", err)
+	}
+	if _, err := parseProxies("10.0.0.0/40"); err == nil {
+		t.Error("expected an invalid network to fail")
+	}
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded []string
+		expected  string
+	}{
+		{"Direct", "203.0.113.7:5000", nil, "203.0.113.7"},
+		{"Untrusted Forward", "203.0.113.7:5000", []string{"198.51.100.1"}, "203.0.113.7"},
+		{"Trusted Forward", "10.1.2.3:5000", []string{"198.51.100.1"}, "198.51.100.1"},
+		{"Proxy Chain", "192.168.1.1:5000", []string{"198.51.100.66, 198.51.100.1", "10.0.0.2"}, "198.51.100.1"},
+		{"Malformed Forward", "10.1.2.3:5000", []string{"unknown"}, "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			r.Header["X-Forwarded-For"] = tt.forwarded
+			if actual := clientIP(r, networks); actual != tt.expected {
+				t.Errorf("received %s; expected %s", actual, tt.expected)
+			}
+		})
+	}
+}
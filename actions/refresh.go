@@ -0,0 +1,153 @@
+package actions
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+	"github.com/pkg/errors"
+)
+
+const (
+	refreshSuccess = "success"
+	refreshFailure = "failure"
+
+	// reasons for which the database is replaced
+	reasonRefresh = "refresh"
+	reasonUpdate  = "update"
+	reasonReload  = "reload"
+
+	// refreshTimeout bounds the download of a listing
+	refreshTimeout = 5 * time.Minute
+	// maxListingSize bounds the size of a downloaded listing
+	maxListingSize = 64 << 20
+)
+
+var (
+	refreshInterval time.Duration
+
+	// refreshes reports the status of background refreshes when enabled
+	refreshes *refresher
+)
+
+// RefreshStatus is the v1 resource model of background database refreshes
+type RefreshStatus struct {
+	Interval    string     `json:"interval"`
+	LastAttempt *time.Time `json:"last_attempt,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// refresher periodically replaces the default database with the latest
+// listing published at its source
+type refresher struct {
+	source   string
+	interval time.Duration
+	client   *http.Client
+
+	mu     sync.Mutex
+	status RefreshStatus
+}
+
+// newRefresher initializes a refresher of the listing at source
+func newRefresher(source string, interval time.Duration) *refresher {
+	return &refresher{
+		source:   source,
+		interval: interval,
+		client:   &http.Client{Timeout: refreshTimeout},
+		status:   RefreshStatus{Interval: interval.String()},
+	}
+}
+
+// run refreshes the database every interval until the context is done
+func (f *refresher) run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := f.refresh(ctx, reasonRefresh); err != nil && ctx.Err() == nil {
+				log.Println("failed to refresh database, continuing with the current one: ", err)
+			}
+		}
+	}
+}
+
+// refresh downloads, parses and validates the listing, persisting it to the
+// database file when one is configured, and replaces the default database
+// only when every step succeeds. Refreshes, updates and reloads are
+// serialized from the download through the replacement.
+func (f *refresher) refresh(ctx context.Context, reason string) (*Update, error) {
+	swapMu.Lock()
+	defer swapMu.Unlock()
+
+	start := time.Now().UTC()
+	db, err := f.fetch(ctx)
+	if err == nil && databaseFile != "" {
+		err = db.Save(databaseFile)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status.LastAttempt = &start
+	if err != nil {
+		f.status.LastError = err.Error()
+		refreshesTotal.WithLabelValues(refreshFailure).Inc()
+		return nil, err
+	}
+
+	update := swapDatabase(db, reason)
+	f.status.LastSuccess = &start
+	f.status.LastError = ""
+	refreshesTotal.WithLabelValues(refreshSuccess).Inc()
+	lastRefresh.Set(float64(start.Unix()))
+	if update.Added+update.Removed+update.Changed > 0 {
+		log.Printf("refreshed database with %d prefixes, version %s\n", db.Len(), db.Version())
+	}
+	return update, nil
+}
+
+// fetch downloads and parses the listing, rejecting listings with less than
+// half the prefixes of the current database as truncated
+func (f *refresher) fetch(ctx context.Context) (*m2v.Database, error) {
+	req, err := http.NewRequest(http.MethodGet, f.source, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid refresh source")
+	}
+	res, err := f.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to download "+f.source)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.New("failed to download " + f.source + "; server responded with " + res.Status)
+	}
+
+	entries, err := m2v.Parse(io.LimitReader(res.Body, maxListingSize))
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 || len(entries) < m2v.Len()/2 {
+		return nil, errors.Errorf("refusing listing of %d prefixes to replace database of %d", len(entries), m2v.Len())
+	}
+
+	updated := time.Now().UTC()
+	if t, err := http.ParseTime(res.Header.Get("Last-Modified")); err == nil {
+		updated = t.UTC()
+	}
+	return m2v.NewDatabase(entries, updated)
+}
+
+// Status returns the outcome of the latest refreshes
+func (f *refresher) Status() *RefreshStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	status := f.status
+	return &status
+}
@@ -0,0 +1,146 @@
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRefresh(t *testing.T) {
+	defer m2v.SetDefault(m2v.Embedded())
+	defer func(prev *refresher) { refreshes = prev }(refreshes)
+
+	// a listing of the embedded database with a single vendor renamed
+	var listing strings.Builder
+	for _, e := range m2v.Embedded().Entries() {
+		if e.Prefix == "84:38:35" {
+			e.Vendor = "Apple Computer"
+		}
+		fmt.Fprintf(&listing, "%s\t%s\n", e.Prefix, e.Vendor)
+	}
+
+	status, body := http.StatusOK, listing.String()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", "Mon, 04 Mar 2019 05:06:07 GMT")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	refreshes = newRefresher(srv.URL, time.Hour)
+	successes := testutil.ToFloat64(refreshesTotal.WithLabelValues(refreshSuccess))
+	failures := testutil.ToFloat64(refreshesTotal.WithLabelValues(refreshFailure))
+
+	update, err := refreshes.refresh(ctx, reasonRefresh)
+	if err != nil {
+		t.Fatal("failed to refresh: ", err)
+	}
+	if update.Changed != 1 || update.Added != 0 || update.Removed != 0 || len(update.Changes) != 1 ||
+		update.Changes[0] != (Change{Prefix: "84:38:35", Previous: "Apple, Inc.", Vendor: "Apple Computer"}) {
+		t.Errorf("recorded unexpected update: %+v", update)
+	}
+	if vendor, _ := m2v.Lookup("84:38:35:77:aa:52"); vendor != "Apple Computer" {
+		t.Errorf("expected the refreshed database to be served, received %q", vendor)
+	}
+	if m2v.Version() == m2v.Embedded().Version() || !m2v.Updated().Equal(time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC)) {
+		t.Errorf("received unexpected database: version %s updated %v", m2v.Version(), m2v.Updated())
+	}
+	version := m2v.Version()
+
+	// failed downloads and truncated listings keep the current database
+	for _, tt := range []struct {
+		status int
+		body   string
+	}{
+		{http.StatusInternalServerError, ""},
+		{http.StatusOK, "84:38:35\tTruncated\n"},
+	} {
+		status, body = tt.status, tt.body
+		if _, err := refreshes.refresh(ctx, reasonRefresh); err == nil {
+			t.Errorf("expected refresh of %d %q to fail", tt.status, tt.body)
+		}
+		if m2v.Version() != version {
+			t.Error("expected a failed refresh to keep the current database")
+		}
+	}
+
+	if n := testutil.ToFloat64(refreshesTotal.WithLabelValues(refreshSuccess)) - successes; n != 1 {
+		t.Errorf("counted %v successful refreshes", n)
+	}
+	if n := testutil.ToFloat64(refreshesTotal.WithLabelValues(refreshFailure)) - failures; n != 2 {
+		t.Errorf("counted %v failed refreshes", n)
+	}
+
+	w := httptest.NewRecorder()
+	newRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/info", nil))
+	var info Info
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+		t.Fatal("failed to decode response: ", err)
+	}
+	if info.Version != version || info.Refresh == nil || info.Refresh.LastSuccess == nil ||
+		info.Refresh.LastAttempt.Before(*info.Refresh.LastSuccess) || info.Refresh.LastError == "" {
+		t.Errorf("received unexpected info: %s", w.Body)
+	}
+}
+
+func TestRefreshConcurrently(t *testing.T) {
+	defer m2v.SetDefault(m2v.Embedded())
+	defer func(name string) { databaseFile = name }(databaseFile)
+
+	dir, err := ioutil.TempDir("", "mac2vnd")
+	if err != nil {
+		t.Fatal("failed to create directory: ", err)
+	}
+	defer os.RemoveAll(dir)
+	databaseFile = filepath.Join(dir, "oui.txt")
+
+	// every download is a listing with a distinct vendor, which is sent
+	// slowly so that refreshes overlap
+	var downloads int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&downloads, 1)
+		time.Sleep(time.Duration(n%3) * 10 * time.Millisecond)
+		for _, e := range m2v.Embedded().Entries() {
+			if e.Prefix == "84:38:35" {
+				e.Vendor = fmt.Sprintf("Apple %d", n)
+			}
+			fmt.Fprintf(w, "%s\t%s\n", e.Prefix, e.Vendor)
+		}
+	}))
+	defer srv.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := newRefresher(srv.URL, 0).refresh(context.Background(), reasonUpdate); err != nil {
+				t.Error("failed to refresh: ", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	saved, err := m2v.Open(databaseFile)
+	if err != nil {
+		t.Fatal("failed to open database file: ", err)
+	}
+	served, _ := m2v.Lookup("84:38:35:77:aa:52")
+	if vendor, _ := saved.Lookup(context.Background(), "84:38:35:77:aa:52"); vendor != served {
+		t.Errorf("expected the database file to hold the served database, received %q; serving %q", vendor, served)
+	}
+}
@@ -0,0 +1,87 @@
+package actions
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+)
+
+const (
+	codeInvalidQuery = "invalid_query"
+
+	// defaultSearchLimit bounds the vendors returned when no limit is
+	// requested, where a limit of zero returns all of them
+	defaultSearchLimit = 100
+)
+
+// SearchResults is the v1 resource model of the vendors matching a query
+type SearchResults struct {
+	Query   string   `json:"query"`
+	Total   int      `json:"total"`
+	Vendors []Vendor `json:"vendors"`
+}
+
+// Info is the v1 resource model describing the vendor database
+type Info struct {
+	Entries int       `json:"entries"`
+	Updated time.Time `json:"updated"`
+	Version string    `json:"version"`
+	// Refresh is reported when the database is refreshed in the background
+	Refresh *RefreshStatus `json:"refresh,omitempty"`
+}
+
+// searchV1 finds the vendors whose name contains, or whose prefix begins
+// with, the q query parameter
+func searchV1(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeProblem(w, newProblem(r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "the search resource only supports GET"))
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		writeProblem(w, newProblem(r, http.StatusBadRequest, codeInvalidQuery, "the q parameter is required"))
+		return
+	}
+
+	limit := defaultSearchLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeProblem(w, newProblem(r, http.StatusBadRequest, codeInvalidQuery, "the limit parameter must be a non-negative integer"))
+			return
+		}
+		limit = n
+	}
+
+	entries := m2v.Search(query)
+	results := &SearchResults{Query: query, Total: len(entries), Vendors: make([]Vendor, 0)}
+	for i, e := range entries {
+		if limit > 0 && i == limit {
+			break
+		}
+		results.Vendors = append(results.Vendors, Vendor{Name: e.Vendor, Prefix: e.Prefix})
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// infoV1 describes the vendor database
+func infoV1(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeProblem(w, newProblem(r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "the info resource only supports GET"))
+		return
+	}
+	info := &Info{
+		Entries: m2v.Len(),
+		Updated: m2v.Updated(),
+		Version: m2v.Version(),
+	}
+	if refreshes != nil {
+		info.Refresh = refreshes.Status()
+	}
+	writeJSON(w, http.StatusOK, info)
+}
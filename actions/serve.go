@@ -1,17 +1,36 @@
 package actions
 
 import (
-	"encoding/json"
+	"bufio"
+	"context"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
-	"github.com/n3integration/mac2vendor"
+	m2v "github.com/n3integration/mac2vendor"
+	"github.com/pkg/errors"
 	"gopkg.in/urfave/cli.v1"
 )
 
-var port uint
+const unixPrefix = "unix:"
+
+var (
+	port              uint
+	listenAddr        string
+	maxBatch          uint = 1000
+	readTimeout            = 10 * time.Second
+	readHeaderTimeout      = 5 * time.Second
+	writeTimeout           = 30 * time.Second
+	idleTimeout            = 2 * time.Minute
+	shutdownTimeout        = 30 * time.Second
+)
 
 func init() {
 	log.SetFlags(log.LstdFlags)
@@ -27,28 +46,508 @@ func init() {
 				Destination: &port,
 				Usage:       "the port to which the service should bind",
 			},
+			cli.UintFlag{
+				Name:        "grpc-port",
+				EnvVar:      "GRPC_PORT",
+				Destination: &grpcPort,
+				Usage:       "the port to which the grpc service should bind, disabled when zero",
+			},
+			cli.StringFlag{
+				Name:        "dns",
+				EnvVar:      "DNS",
+				Destination: &dnsAddr,
+				Usage:       "the host:port on which to answer dns queries for vendors over udp and tcp, disabled when empty",
+			},
+			cli.StringFlag{
+				Name:        "dns-zone",
+				EnvVar:      "DNS_ZONE",
+				Value:       dnsZone,
+				Destination: &dnsZone,
+				Usage:       "the zone under which the dns service answers oui and mac names",
+			},
+			cli.UintFlag{
+				Name:        "dns-max-connections",
+				EnvVar:      "DNS_MAX_CONNECTIONS",
+				Value:       dnsMaxConns,
+				Destination: &dnsMaxConns,
+				Usage:       "the maximum number of concurrent tcp connections to the dns service, unlimited when zero",
+			},
+			cli.StringFlag{
+				Name:        "listen",
+				EnvVar:      "LISTEN",
+				Destination: &listenAddr,
+				Usage:       "the host:port or unix:/path/to/socket to which the service should bind, overriding --port",
+			},
+			cli.UintFlag{
+				Name:        "max-batch",
+				EnvVar:      "MAX_BATCH",
+				Value:       maxBatch,
+				Destination: &maxBatch,
+				Usage:       "the maximum number of addresses accepted by a batch lookup",
+			},
+			cli.UintFlag{
+				Name:        "max-streams",
+				EnvVar:      "MAX_STREAMS",
+				Value:       maxStreams,
+				Destination: &maxStreams,
+				Usage:       "the maximum number of concurrent streaming lookups, unlimited when zero",
+			},
+			cli.UintFlag{
+				Name:        "max-client-streams",
+				EnvVar:      "MAX_CLIENT_STREAMS",
+				Value:       maxClientStreams,
+				Destination: &maxClientStreams,
+				Usage:       "the maximum number of concurrent streaming lookups of each client, unlimited when zero",
+			},
+			cli.Float64Flag{
+				Name:        "stream-rate",
+				EnvVar:      "STREAM_RATE",
+				Value:       streamRate,
+				Destination: &streamRate,
+				Usage:       "the addresses per second resolved for each streaming lookup, unlimited when zero",
+			},
+			cli.DurationFlag{
+				Name:        "stream-idle-timeout",
+				EnvVar:      "STREAM_IDLE_TIMEOUT",
+				Value:       streamIdleTimeout,
+				Destination: &streamIdleTimeout,
+				Usage:       "the duration after which streaming lookups without messages are closed, unlimited when zero",
+			},
+			cli.DurationFlag{
+				Name:        "max-database-age",
+				EnvVar:      "MAX_DATABASE_AGE",
+				Destination: &maxDatabaseAge,
+				Usage:       "the database age beyond which the service reports it is not ready, disabled when zero",
+			},
+			cli.DurationFlag{
+				Name:        "refresh-interval",
+				EnvVar:      "REFRESH_INTERVAL",
+				Destination: &refreshInterval,
+				Usage:       "the interval at which the database is downloaded and replaced in the background, disabled when zero",
+			},
+			cli.StringFlag{
+				Name:        "refresh-source",
+				EnvVar:      "REFRESH_SOURCE",
+				Value:       source,
+				Destination: &source,
+				Usage:       "the url of the oui listing from which the database is refreshed",
+			},
+			cli.DurationFlag{
+				Name:        "cache-max-age",
+				EnvVar:      "CACHE_MAX_AGE",
+				Value:       cacheMaxAge,
+				Destination: &cacheMaxAge,
+				Usage:       "the duration for which clients may cache lookup responses",
+			},
+			cli.StringFlag{
+				Name:        "api-keys",
+				EnvVar:      "API_KEYS",
+				Destination: &apiKeysFile,
+				Usage:       "a file listing the api keys, one per line, required of clients when provided",
+			},
+			cli.StringFlag{
+				Name:        "admin-keys",
+				EnvVar:      "ADMIN_KEYS",
+				Destination: &adminKeysFile,
+				Usage:       "a file listing the keys, one per line, accepted by the admin api, which is disabled when empty",
+			},
+			cli.StringFlag{
+				Name:        "audit-log",
+				EnvVar:      "AUDIT_LOG",
+				Destination: &auditLogDest,
+				Usage:       "where changes made through the admin api are recorded: stdout, stderr, syslog, syslog://host:port or a file path",
+			},
+			cli.StringFlag{
+				Name:        "database",
+				EnvVar:      "DATABASE",
+				Destination: &databaseFile,
+				Usage:       "an oui listing served in place of the embedded database, to which updates are persisted",
+			},
+			cli.StringFlag{
+				Name:        "overrides",
+				EnvVar:      "OVERRIDES",
+				Destination: &overridesFile,
+				Usage:       "a file of addresses or prefixes and the vendors that override the database, to which changes are persisted",
+			},
+			cli.Float64Flag{
+				Name:        "rate-limit",
+				EnvVar:      "RATE_LIMIT",
+				Destination: &rateLimit,
+				Usage:       "the requests per second permitted to each api key or client address, unlimited when zero",
+			},
+			cli.UintFlag{
+				Name:        "rate-burst",
+				EnvVar:      "RATE_BURST",
+				Value:       rateBurst,
+				Destination: &rateBurst,
+				Usage:       "the number of requests a client may burst above the rate limit",
+			},
+			cli.StringFlag{
+				Name:        "trusted-proxies",
+				EnvVar:      "TRUSTED_PROXIES",
+				Destination: &trustedProxies,
+				Usage:       "a comma separated list of proxy addresses or networks trusted to set X-Forwarded-For",
+			},
+			cli.StringFlag{
+				Name:        "access-log",
+				EnvVar:      "ACCESS_LOG",
+				Destination: &accessLogDest,
+				Usage:       "where requests are logged: stdout, stderr, syslog, syslog://host:port or a file path",
+			},
+			cli.StringFlag{
+				Name:        "access-log-format",
+				EnvVar:      "ACCESS_LOG_FORMAT",
+				Value:       accessLogFormat,
+				Destination: &accessLogFormat,
+				Usage:       "the format in which requests are logged (text, json, logfmt or combined)",
+			},
+			cli.StringFlag{
+				Name:        "log-level",
+				EnvVar:      "LOG_LEVEL",
+				Value:       logLevelName,
+				Destination: &logLevelName,
+				Usage:       "the minimum level of logged requests (debug, info, warn or error), where probes are debug, client errors warn and server errors error",
+			},
+			cli.StringFlag{
+				Name:        "cors-origins",
+				EnvVar:      "CORS_ORIGINS",
+				Destination: &corsOrigins,
+				Usage:       "a comma separated list of origins, such as * or https://*.example.com, permitted to call the service from browsers, disabled when empty",
+			},
+			cli.StringFlag{
+				Name:        "cors-methods",
+				EnvVar:      "CORS_METHODS",
+				Value:       corsMethods,
+				Destination: &corsMethods,
+				Usage:       "a comma separated list of the methods permitted to cross-origin requests",
+			},
+			cli.StringFlag{
+				Name:        "cors-headers",
+				EnvVar:      "CORS_HEADERS",
+				Value:       corsHeaders,
+				Destination: &corsHeaders,
+				Usage:       "a comma separated list of the request headers permitted to cross-origin requests",
+			},
+			cli.DurationFlag{
+				Name:        "cors-max-age",
+				EnvVar:      "CORS_MAX_AGE",
+				Value:       corsMaxAge,
+				Destination: &corsMaxAge,
+				Usage:       "the duration for which browsers may cache preflight responses",
+			},
+			cli.DurationFlag{
+				Name:        "read-timeout",
+				EnvVar:      "READ_TIMEOUT",
+				Value:       readTimeout,
+				Destination: &readTimeout,
+				Usage:       "the maximum duration for reading an entire request",
+			},
+			cli.DurationFlag{
+				Name:        "read-header-timeout",
+				EnvVar:      "READ_HEADER_TIMEOUT",
+				Value:       readHeaderTimeout,
+				Destination: &readHeaderTimeout,
+				Usage:       "the maximum duration for reading request headers",
+			},
+			cli.DurationFlag{
+				Name:        "write-timeout",
+				EnvVar:      "WRITE_TIMEOUT",
+				Value:       writeTimeout,
+				Destination: &writeTimeout,
+				Usage:       "the maximum duration before timing out writes of a response",
+			},
+			cli.DurationFlag{
+				Name:        "idle-timeout",
+				EnvVar:      "IDLE_TIMEOUT",
+				Value:       idleTimeout,
+				Destination: &idleTimeout,
+				Usage:       "the maximum duration to wait for the next request on a keep-alive connection",
+			},
+			cli.DurationFlag{
+				Name:        "shutdown-timeout",
+				EnvVar:      "SHUTDOWN_TIMEOUT",
+				Value:       shutdownTimeout,
+				Destination: &shutdownTimeout,
+				Usage:       "the maximum duration to wait for in-flight requests to complete on shutdown",
+			},
+			cli.StringFlag{
+				Name:        "tls-cert",
+				EnvVar:      "TLS_CERT",
+				Destination: &tlsCert,
+				Usage:       "the pem encoded certificate with which to serve tls, reloaded when rotated",
+			},
+			cli.StringFlag{
+				Name:        "tls-key",
+				EnvVar:      "TLS_KEY",
+				Destination: &tlsKey,
+				Usage:       "the pem encoded private key of the tls certificate",
+			},
+			cli.StringFlag{
+				Name:        "tls-client-ca",
+				EnvVar:      "TLS_CLIENT_CA",
+				Destination: &tlsClientCA,
+				Usage:       "the pem encoded certificate authorities required to have signed client certificates",
+			},
+			cli.StringFlag{
+				Name:        "tls-min-version",
+				EnvVar:      "TLS_MIN_VERSION",
+				Value:       tlsMinVersion,
+				Destination: &tlsMinVersion,
+				Usage:       "the minimum tls version accepted (1.0, 1.1, 1.2 or 1.3)",
+			},
 		},
 	})
 }
 
 func serveAction(_ *cli.Context) error {
-	http.HandleFunc("/", logger(lookup))
-	log.Printf("Service listening at 127.0.0.1:%d\n", port)
-	return http.ListenAndServe(fmt.Sprintf(":%d", port), nil)
+	addr := listenAddr
+	if addr == "" {
+		addr = fmt.Sprintf(":%d", port)
+	}
+
+	if apiKeysFile != "" {
+		keys, err := loadAPIKeys(apiKeysFile)
+		if err != nil {
+			return err
+		}
+		apiKeys = keys
+	}
+	if adminKeysFile != "" {
+		keys, err := loadAPIKeys(adminKeysFile)
+		if err != nil {
+			return err
+		}
+		adminKeys = keys
+
+		sink, err := openSink(auditLogDest)
+		if err != nil {
+			return err
+		}
+		defer sink.Close()
+		auditLog = sink
+	}
+	if databaseFile != "" {
+		db, err := m2v.Open(databaseFile)
+		if err != nil {
+			return err
+		}
+		m2v.SetDefault(db)
+	}
+	if overridesFile != "" {
+		o, err := loadOverrides(overridesFile)
+		if err != nil {
+			return err
+		}
+		overrides.Store(o)
+	}
+	if rateLimit > 0 {
+		limiter = newRateLimiter(rateLimit, rateBurst)
+	}
+	networks, err := parseProxies(trustedProxies)
+	if err != nil {
+		return err
+	}
+	proxies = networks
+
+	if corsOrigins != "" {
+		if crossOrigin, err = newCORSPolicy(corsOrigins, corsMethods, corsHeaders, corsMaxAge); err != nil {
+			return err
+		}
+	}
+
+	accessLog, err := newAccessLog(accessLogFormat, logLevelName, accessLogDest)
+	if err != nil {
+		return err
+	}
+	defer accessLog.Close()
+	accessLogger = accessLog
+
+	srv := newServer()
+	if tlsCert != "" || tlsKey != "" || tlsClientCA != "" {
+		config, err := newTLSConfig(tlsCert, tlsKey, tlsClientCA, tlsMinVersion)
+		if err != nil {
+			return err
+		}
+		srv.TLSConfig = config
+	}
+
+	ln, err := listen(addr)
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	// the web service is stopped when the grpc service fails
+	grpcErr := make(chan error, 1)
+	if grpcPort > 0 {
+		gln, err := listen(net.JoinHostPort(listenHost(addr), strconv.FormatUint(uint64(grpcPort), 10)))
+		if err != nil {
+			ln.Close()
+			return err
+		}
+
+		gs := newGRPCServer(srv.TLSConfig)
+		defer stopGRPC(gs)
+		go func() {
+			if err := gs.Serve(gln); err != nil {
+				grpcErr <- errors.Wrap(err, "grpc service failed")
+				select {
+				case stop <- syscall.SIGTERM:
+				default:
+				}
+			}
+		}()
+		log.Printf("gRPC service listening at %s\n", gln.Addr())
+	}
+
+	if dnsAddr != "" {
+		ds, err := listenDNS(dnsAddr, dnsZone)
+		if err != nil {
+			ln.Close()
+			return err
+		}
+
+		defer ds.Close()
+		ds.Serve()
+		log.Printf("DNS service listening at %s for %s\n", ds.Addr(), ds.zone)
+	}
+
+	if refreshInterval > 0 {
+		refreshes = newRefresher(source, refreshInterval)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go refreshes.run(ctx)
+		log.Printf("Refreshing database from %s every %v\n", source, refreshInterval)
+	}
+
+	log.Printf("Service listening at %s\n", ln.Addr())
+	err = serve(srv, ln, stop)
+	select {
+	case gerr := <-grpcErr:
+		return gerr
+	default:
+		return err
+	}
+}
+
+// listenHost returns the host of the web service address, which is the
+// loopback interface when listening on a unix domain socket
+func listenHost(addr string) string {
+	if strings.HasPrefix(addr, unixPrefix) {
+		return "localhost"
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return ""
+	}
+	return host
 }
 
-// Mac2Vnd is a resource model
+// listen binds to a tcp address or, when prefixed with "unix:", to a unix
+// domain socket, replacing any stale socket left at the path
+func listen(addr string) (net.Listener, error) {
+	if !strings.HasPrefix(addr, unixPrefix) {
+		ln, err := net.Listen("tcp", addr)
+		return ln, errors.Wrap(err, "failed to listen on "+addr)
+	}
+
+	path := strings.TrimPrefix(addr, unixPrefix)
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		// a socket is only stale when nothing accepts connections on it
+		conn, err := net.DialTimeout("unix", path, time.Second)
+		if err == nil {
+			conn.Close()
+			return nil, errors.Errorf("failed to listen on %s: the socket is in use", addr)
+		}
+		if !isRefused(err) {
+			return nil, errors.Wrap(err, "failed to probe socket "+path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, errors.Wrap(err, "failed to remove stale socket "+path)
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	return ln, errors.Wrap(err, "failed to listen on "+addr)
+}
+
+// newServer initializes the web service with its configured timeouts
+func newServer() *http.Server {
+	router := newRouter()
+	return &http.Server{
+		Handler:           logger(instrument(router, cors(guard(router)))),
+		ReadTimeout:       readTimeout,
+		ReadHeaderTimeout: readHeaderTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+	}
+}
+
+// serve accepts connections on ln, over tls when the server is configured
+// for it, until a signal is received from stop, at which point in-flight
+// requests are drained before returning
+func serve(srv *http.Server, ln net.Listener, stop <-chan os.Signal) error {
+	errc := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			errc <- srv.ServeTLS(ln, "", "")
+			return
+		}
+		errc <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case sig := <-stop:
+		log.Printf("received %v, draining in-flight requests\n", sig)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		return errors.Wrap(err, "failed to shutdown gracefully")
+	}
+	if err := <-errc; err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// newRouter maps the versioned api, operational endpoints and the legacy
+// lookup path to their handlers
+func newRouter() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc(apiPrefix+"lookup", lookupBatch)
+	mux.HandleFunc(apiPrefix+"lookup/", lookupV1)
+	mux.HandleFunc(apiPrefix+"search", searchV1)
+	mux.HandleFunc(apiPrefix+"info", infoV1)
+	mux.HandleFunc(streamPath, lookupStream)
+	mux.HandleFunc(apiPrefix, notFoundV1)
+	mux.Handle(metricsPath, metricsHandler())
+	mux.HandleFunc(healthPath, healthz)
+	mux.HandleFunc(readinessPath, readyz)
+	mux.Handle(uiPath, uiHandler())
+	mux.HandleFunc(openapiPath, openapi)
+	mux.Handle(adminPrefix, adminHandler())
+	mux.HandleFunc("/", lookup)
+	return mux
+}
+
+// Mac2Vnd is the legacy resource model
 type Mac2Vnd struct {
 	Mac    string `json:"mac,omitempty"`
 	Vendor string `json:"vendor,omitempty"`
-	Error  error  `json:"error,omitempty"`
+	Error  string `json:"error,omitempty"`
 }
 
 // newMac2Vnd initializes a new response
 func newMac2Vnd(mac, vendor string, err error) *Mac2Vnd {
 	if err != nil {
 		return &Mac2Vnd{
-			Error: err,
+			Error: err.Error(),
 		}
 	}
 	return &Mac2Vnd{
@@ -73,50 +572,79 @@ func (i *interceptor) WriteHeader(statusCode int) {
 }
 
 func (i *interceptor) Write(p []byte) (n int, err error) {
+	if i.Status == 0 {
+		i.Status = http.StatusOK
+	}
 	i.Bytes += int64(len(p))
 	return i.delegate.Write(p)
 }
 
-// logger is logging middleware
-func logger(next http.HandlerFunc) http.HandlerFunc {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		wi := &interceptor{delegate: w}
-		defer func() {
-			log.Println(r.RemoteAddr, " ", r.Method, " ", r.RequestURI, " ", r.Proto, " ", wi.Status, " ", wi.Bytes)
-		}()
-		next.ServeHTTP(wi, r)
-	})
+// Unwrap returns the delegate, so that response controllers reach the
+// underlying connection
+func (i *interceptor) Unwrap() http.ResponseWriter {
+	return i.delegate
+}
+
+// Flush flushes buffered data to the client when supported by the delegate
+func (i *interceptor) Flush() {
+	if f, ok := i.delegate.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack takes over the connection when supported by the delegate, as when
+// upgrading to a websocket
+func (i *interceptor) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := i.delegate.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response does not support hijacking")
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil {
+		i.Status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
 }
 
-// lookup provides the mac address to vendor lookup service handler
+// StatusCode returns the response status, which is implicitly 200 when the
+// handler did not write a header
+func (i *interceptor) StatusCode() int {
+	if i.Status == 0 {
+		return http.StatusOK
+	}
+	return i.Status
+}
+
+// lookup provides the legacy mac address to vendor lookup service handler
 func lookup(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		w.Header().Set("Allow", http.MethodGet)
+		writeJSON(w, http.StatusMethodNotAllowed, &Mac2Vnd{Error: "method not allowed"})
 		return
 	}
 
 	mac := r.URL.Path[1:]
-	vendor, err := mac2vendor.Lookup(mac)
+	var vendor, tag string
+	hw, err := net.ParseMAC(mac)
+	if err == nil {
+		tag = etag(hw)
+		vendor, err = vendorOf(hw)
+	}
+	countLookup(vendor, err)
 	response := newMac2Vnd(mac, vendor, err)
-	json, err := json.Marshal(response)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	if response.Error != nil {
 		status := http.StatusNotFound
-		switch response.Error.(type) {
+		switch err.(type) {
 		case *net.AddrError:
 			status = http.StatusBadRequest
 		}
-		http.Error(w, string(json), status)
+		writeJSON(w, status, response)
 		return
 	}
 
-	if _, err := w.Write(json); err != nil {
-		log.Println("failed to write response: ", err)
+	if cacheable(w, r, tag) {
+		return
 	}
+	writeJSON(w, http.StatusOK, response)
 }
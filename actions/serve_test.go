@@ -2,12 +2,20 @@ package actions
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestServe(t *testing.T) {
@@ -51,6 +59,91 @@ func TestServe(t *testing.T) {
 	}
 }
 
+func TestServeLegacyError(t *testing.T) {
+	w := httptest.NewRecorder()
+	newRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/not-a-mac", nil))
+
+	var response Mac2Vnd
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal("failed to decode response: ", err)
+	}
+	if w.Code != http.StatusBadRequest || response.Error == "" {
+		t.Errorf("expected the error message to be returned, received %v: %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != contentTypeJSON {
+		t.Errorf("received unexpected content type: %v", ct)
+	}
+}
+
+func TestServeV1(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		code        int
+		contentType string
+		problem     string
+	}{
+		{"Lookup", http.MethodGet, "/v1/lookup/84-38-35-77-AA-52", http.StatusOK, contentTypeJSON, ""},
+		{"Unknown Vendor", http.MethodGet, "/v1/lookup/52:54:00:12:34:56", http.StatusOK, contentTypeJSON, ""},
+		{"Invalid MAC", http.MethodGet, "/v1/lookup/not-a-mac", http.StatusBadRequest, contentTypeProblem, codeInvalidMAC},
+		{"Unsupported Method", http.MethodDelete, "/v1/lookup/84:38:35:77:aa:52", http.StatusMethodNotAllowed, contentTypeProblem, codeMethodNotAllowed},
+		{"Unknown Resource", http.MethodGet, "/v1/vendors", http.StatusNotFound, contentTypeProblem, codeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newRouter().ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.code {
+				t.Errorf("received unexpected status code: %v; expected %v", w.Code, tt.code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Errorf("received unexpected content type: %v; expected %v", ct, tt.contentType)
+			}
+			if tt.problem == "" {
+				return
+			}
+
+			var problem Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatal("failed to decode problem: ", err)
+			}
+			if problem.Code != tt.problem || problem.Status != tt.code || problem.Detail == "" {
+				t.Errorf("received unexpected problem: %+v", problem)
+			}
+		})
+	}
+
+	t.Run("Response", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/lookup/52:54:00:12:34:56", nil))
+
+		var addr Address
+		if err := json.Unmarshal(w.Body.Bytes(), &addr); err != nil {
+			t.Fatal("failed to decode response: ", err)
+		}
+		expected := Address{
+			MAC:        "52:54:00:12:34:56",
+			Normalized: "52:54:00:12:34:56",
+			Prefix:     "52:54:00",
+			Flags:      Flags{Local: true, Virtual: true, Hypervisor: "QEMU/KVM"},
+		}
+		if addr != expected {
+			t.Errorf("received unexpected response: %+v; expected %+v", addr, expected)
+		}
+
+		w = httptest.NewRecorder()
+		newRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/lookup/84-38-35-77-AA-52", nil))
+		addr = Address{}
+		if err := json.Unmarshal(w.Body.Bytes(), &addr); err != nil {
+			t.Fatal("failed to decode response: ", err)
+		}
+		if addr.Normalized != "84:38:35:77:aa:52" || addr.Vendor == nil || addr.Vendor.Name != "Apple, Inc." {
+			t.Errorf("received unexpected response: %s", w.Body)
+		}
+	})
+}
+
 func TestLogger(t *testing.T) {
 	next := http.NotFound
 	out := new(bytes.Buffer)
@@ -64,3 +157,96 @@ func TestLogger(t *testing.T) {
 		t.Errorf("expected logged request to include response status code, but not found: %s", out)
 	}
 }
+
+func TestServeGracefully(t *testing.T) {
+	ln, err := listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal("failed to listen: ", err)
+	}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte("drained"))
+	})}
+
+	stop := make(chan os.Signal, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(srv, ln, stop)
+	}()
+
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(resp.Body)
+		body <- string(b)
+	}()
+
+	<-started
+	stop <- syscall.SIGTERM
+	select {
+	case err := <-done:
+		t.Fatal("expected in-flight requests to be drained before returning: ", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if b := <-body; b != "drained" {
+		t.Errorf("expected in-flight request to complete, received %q", b)
+	}
+	if err := <-done; err != nil {
+		t.Error("failed to shutdown gracefully: ", err)
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mac2vnd")
+	if err != nil {
+		t.Fatal("failed to create directory: ", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "mac2vnd.sock")
+	for i := 0; i < 2; i++ {
+		ln, err := listen(unixPrefix + path)
+		if err != nil {
+			t.Fatal("failed to listen: ", err)
+		}
+		ln.(*net.UnixListener).SetUnlinkOnClose(false)
+
+		stop := make(chan os.Signal, 1)
+		done := make(chan error, 1)
+		go func() {
+			done <- serve(newServer(), ln, stop)
+		}()
+
+		client := &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}}
+		resp, err := client.Get("http://mac2vnd/v1/lookup/84:38:35:77:aa:52")
+		if err != nil {
+			t.Fatal("failed to request over unix socket: ", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("received unexpected status code: %v", resp.StatusCode)
+		}
+		if _, err := listen(unixPrefix + path); err == nil {
+			t.Error("expected a socket in use to be refused")
+		}
+
+		stop <- syscall.SIGINT
+		if err := <-done; err != nil {
+			t.Error("failed to shutdown gracefully: ", err)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	streamPath = apiPrefix + "stream"
+
+	codeUpgradeRequired = "upgrade_required"
+	codeTooManyStreams  = "too_many_streams"
+	codeForbiddenOrigin = "forbidden_origin"
+)
+
+var (
+	maxStreams        uint = 100
+	maxClientStreams  uint = 10
+	streamRate             = 1000.0
+	streamIdleTimeout      = time.Minute
+
+	// openStreams counts the streams currently served
+	openStreams int64
+
+	// clientStreams counts the streams currently served to each client
+	clientStreams   = make(map[string]uint)
+	clientStreamsMu sync.Mutex
+)
+
+// streamLimits are the limits of a stream, taken when it is accepted
+type streamLimits struct {
+	rate         float64
+	idleTimeout  time.Duration
+	writeTimeout time.Duration
+	batch        uint
+}
+
+// lookupStream upgrades the request to a websocket over which clients send
+// addresses and receive the result of each as it is resolved. Each message
+// holds a json array of addresses, a json string or whitespace separated
+// addresses, and each result is sent as a json batch result, indexed in the
+// order the addresses were received over the connection.
+//
+// Messages are read only once the results of the previous one were written,
+// so that clients sending faster than they consume results are slowed by the
+// connection's flow control, and those not consuming results within the
+// write timeout are disconnected. Each connection is limited to streamRate
+// addresses per second and is closed once idle for streamIdleTimeout, and
+// each client, identified by its api key or else its address, is limited to
+// maxClientStreams connections.
+func lookupStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeProblem(w, newProblem(r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "the stream resource only supports GET"))
+		return
+	}
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		w.Header().Set("Upgrade", "websocket")
+		w.Header().Set("Connection", "Upgrade")
+		writeProblem(w, newProblem(r, http.StatusUpgradeRequired, codeUpgradeRequired, "the stream resource requires a websocket"))
+		return
+	}
+
+	origin, err := streamOrigin(r)
+	if err != nil {
+		writeProblem(w, newProblem(r, http.StatusForbidden, codeForbiddenOrigin, err.Error()))
+		return
+	}
+
+	defer atomic.AddInt64(&openStreams, -1)
+	if n := atomic.AddInt64(&openStreams, 1); maxStreams > 0 && n > int64(maxStreams) {
+		w.Header().Set("Retry-After", "1")
+		writeProblem(w, newProblem(r, http.StatusServiceUnavailable, codeTooManyStreams,
+			fmt.Sprintf("the service is limited to %d concurrent streams", maxStreams)))
+		return
+	}
+
+	client, _ := identify(clientIP(r, proxies), credential(r), apiKeys)
+	defer releaseStream(client)
+	if n := acquireStream(client); maxClientStreams > 0 && n > maxClientStreams {
+		w.Header().Set("Retry-After", "1")
+		writeProblem(w, newProblem(r, http.StatusServiceUnavailable, codeTooManyStreams,
+			fmt.Sprintf("clients are limited to %d concurrent streams", maxClientStreams)))
+		return
+	}
+
+	limits := streamLimits{
+		rate:         streamRate,
+		idleTimeout:  streamIdleTimeout,
+		writeTimeout: writeTimeout,
+		batch:        maxBatch,
+	}
+	websocket.Server{
+		Handshake: func(config *websocket.Config, _ *http.Request) error {
+			config.Origin = origin
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			serveStream(ws, r, limits)
+		},
+	}.ServeHTTP(w, r)
+}
+
+// acquireStream counts a stream of the client, returning the number of its
+// streams including this one
+func acquireStream(client string) uint {
+	clientStreamsMu.Lock()
+	defer clientStreamsMu.Unlock()
+	clientStreams[client]++
+	return clientStreams[client]
+}
+
+// releaseStream discounts a stream of the client
+func releaseStream(client string) {
+	clientStreamsMu.Lock()
+	defer clientStreamsMu.Unlock()
+	if clientStreams[client]--; clientStreams[client] == 0 {
+		delete(clientStreams, client)
+	}
+}
+
+// streamOrigin accepts connections from clients other than browsers, which
+// do not send an origin, from pages served by the service itself and from
+// the origins permitted by the cors policy
+func streamOrigin(r *http.Request) (*url.URL, error) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid origin")
+	}
+	if strings.EqualFold(u.Host, r.Host) || (crossOrigin != nil && crossOrigin.allows(origin)) {
+		return u, nil
+	}
+	return nil, errors.Errorf("origin %s is not permitted to stream", origin)
+}
+
+// serveStream resolves the addresses received over the websocket until the
+// client disconnects, idles or stops consuming results
+func serveStream(ws *websocket.Conn, r *http.Request, limits streamLimits) {
+	defer ws.Close()
+	ws.MaxPayloadBytes = int(limits.batch) * maxItemBytes
+
+	var limit *rateLimiter
+	if limits.rate > 0 {
+		limit = newRateLimiter(limits.rate, uint(limits.rate))
+	}
+
+	index := 0
+	send := func(result *BatchResult) bool {
+		index++
+		if limits.writeTimeout > 0 {
+			ws.SetWriteDeadline(time.Now().Add(limits.writeTimeout))
+		}
+		return websocket.JSON.Send(ws, result) == nil
+	}
+
+	// the deadlines of the server apply to the request that was upgraded
+	ws.SetDeadline(time.Time{})
+	for {
+		if limits.idleTimeout > 0 {
+			ws.SetReadDeadline(time.Now().Add(limits.idleTimeout))
+		}
+		var msg string
+		if err := websocket.Message.Receive(ws, &msg); err != nil {
+			return
+		}
+
+		inputs, err := parseStreamMessage(msg, limits.batch)
+		if err != nil {
+			result := &BatchResult{Index: index, Input: msg, Error: newProblem(r, http.StatusBadRequest, codeInvalidBody, err.Error())}
+			if !send(result) {
+				return
+			}
+			continue
+		}
+
+		for _, input := range inputs {
+			if limit != nil {
+				for ok, wait := limit.allow(""); !ok; ok, wait = limit.allow("") {
+					time.Sleep(wait)
+				}
+			}
+			if !send(resolveInput(r, index, input)) {
+				return
+			}
+		}
+	}
+}
+
+// parseStreamMessage reads the addresses of a json array, a json string or
+// whitespace separated addresses, at most limit of them
+func parseStreamMessage(msg string, limit uint) ([]string, error) {
+	msg = strings.TrimSpace(msg)
+	var inputs []string
+	switch {
+	case strings.HasPrefix(msg, "["):
+		if err := json.Unmarshal([]byte(msg), &inputs); err != nil {
+			return nil, errors.New("expected a json array of mac addresses")
+		}
+	case strings.HasPrefix(msg, `"`):
+		var input string
+		if err := json.Unmarshal([]byte(msg), &input); err != nil {
+			return nil, errors.New("expected a json string")
+		}
+		inputs = []string{input}
+	default:
+		inputs = strings.Fields(msg)
+	}
+	if uint(len(inputs)) > limit {
+		return nil, errors.Errorf("messages are limited to %d addresses", limit)
+	}
+	return inputs, nil
+}
@@ -0,0 +1,138 @@
+package actions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestStream(t *testing.T) {
+	defer func(n, c uint, rate float64, idle time.Duration) {
+		maxStreams, maxClientStreams, streamRate, streamIdleTimeout = n, c, rate, idle
+	}(maxStreams, maxClientStreams, streamRate, streamIdleTimeout)
+	maxStreams, maxClientStreams, streamRate, streamIdleTimeout = 1, 0, 0, time.Minute
+
+	srv := httptest.NewServer(newServer().Handler)
+	defer srv.Close()
+	endpoint := "ws" + strings.TrimPrefix(srv.URL, "http") + streamPath
+
+	ws, err := websocket.Dial(endpoint, "", srv.URL)
+	if err != nil {
+		t.Fatal("failed to connect: ", err)
+	}
+	defer ws.Close()
+
+	receive := func(n int) []BatchResult {
+		results := make([]BatchResult, n)
+		for i := range results {
+			ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+			if err := websocket.JSON.Receive(ws, &results[i]); err != nil {
+				t.Fatal("failed to receive result: ", err)
+			}
+		}
+		return results
+	}
+
+	websocket.Message.Send(ws, `["84:38:35:77:aa:52", "not-a-mac"]`)
+	websocket.Message.Send(ws, "00:00:0c:00:00:01\n52:54:00:12:34:56")
+	websocket.Message.Send(ws, `["unterminated`)
+	results := receive(5)
+	for i, result := range results {
+		if result.Index != i {
+			t.Errorf("received result %d at %d", result.Index, i)
+		}
+	}
+	if results[0].Address == nil || results[0].Address.Vendor == nil || results[0].Address.Vendor.Name != "Apple, Inc." {
+		t.Errorf("received unexpected result: %+v", results[0])
+	}
+	if results[1].Error == nil || results[1].Error.Code != codeInvalidMAC {
+		t.Errorf("expected an invalid address to be reported: %+v", results[1])
+	}
+	if results[2].Input != "00:00:0c:00:00:01" || results[2].Address == nil || results[3].Address == nil {
+		t.Errorf("received unexpected results: %+v %+v", results[2], results[3])
+	}
+	if results[4].Error == nil || results[4].Error.Code != codeInvalidBody {
+		t.Errorf("expected an invalid message to be reported: %+v", results[4])
+	}
+
+	t.Run("Limits", func(t *testing.T) {
+		if _, err := websocket.Dial(endpoint, "", srv.URL); err == nil {
+			t.Error("expected streams beyond the limit to be refused")
+		}
+
+		res, err := http.Get(srv.URL + streamPath)
+		if err != nil {
+			t.Fatal("failed to request stream: ", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusUpgradeRequired {
+			t.Errorf("received unexpected status code: %v", res.StatusCode)
+		}
+	})
+
+	// the limits are changed only once the server closed the stream
+	closed := func() {
+		for deadline := time.Now().Add(5 * time.Second); atomic.LoadInt64(&openStreams) > 0; {
+			if time.Now().After(deadline) {
+				t.Fatal("expected the stream to be closed")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	ws.Close()
+	closed()
+	maxStreams, maxClientStreams = 0, 1
+
+	t.Run("Client Limits", func(t *testing.T) {
+		ws, err := websocket.Dial(endpoint, "", srv.URL)
+		if err != nil {
+			t.Fatal("failed to connect: ", err)
+		}
+		defer closed()
+		defer ws.Close()
+
+		if _, err := websocket.Dial(endpoint, "", srv.URL); err == nil {
+			t.Error("expected streams beyond the client limit to be refused")
+		}
+	})
+
+	streamRate, streamIdleTimeout = 100, 100*time.Millisecond
+
+	t.Run("Origin", func(t *testing.T) {
+		if _, err := websocket.Dial(endpoint, "", "https://evil.example.com"); err == nil {
+			t.Error("expected a foreign origin to be refused")
+		}
+	})
+
+	t.Run("Rate", func(t *testing.T) {
+		ws, err := websocket.Dial(endpoint, "", srv.URL)
+		if err != nil {
+			t.Fatal("failed to connect: ", err)
+		}
+		defer ws.Close()
+
+		start := time.Now()
+		websocket.Message.Send(ws, strings.Repeat("84:38:35:77:aa:52 ", 120))
+		for i := 0; i < 120; i++ {
+			var result BatchResult
+			if err := websocket.JSON.Receive(ws, &result); err != nil {
+				t.Fatal("failed to receive result: ", err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+			t.Errorf("expected results beyond the burst to be throttled, received all in %v", elapsed)
+		}
+
+		// idle streams are closed
+		ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var msg string
+		if err := websocket.Message.Receive(ws, &msg); err == nil {
+			t.Errorf("expected an idle stream to be closed, received %q", msg)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package actions
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	tlsCert       string
+	tlsKey        string
+	tlsClientCA   string
+	tlsMinVersion = "1.2"
+
+	// tlsReloadInterval bounds how often the certificate files are checked
+	// for modifications
+	tlsReloadInterval = 10 * time.Second
+
+	tlsVersions = map[string]uint16{
+		"1.0": tls.VersionTLS10,
+		"1.1": tls.VersionTLS11,
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+)
+
+// certReloader provides the most recent certificate and client certificate
+// authorities found on disk, reloading them after the files are rotated
+type certReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime time.Time
+	checked time.Time
+}
+
+// newTLSConfig initializes the tls configuration of the service from the
+// certificate, key and optional client ca files
+func newTLSConfig(certFile, keyFile, caFile, minVersion string) (*tls.Config, error) {
+	version, ok := tlsVersions[minVersion]
+	if !ok {
+		return nil, errors.Errorf("unsupported minimum tls version: %s", minVersion)
+	}
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("both a tls certificate and key are required")
+	}
+
+	reloader := &certReloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+
+	// the protocols are those net/http adds to its own copy of the config,
+	// which the per-client configs below would otherwise be cloned without
+	config := &tls.Config{
+		MinVersion:     version,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+	if caFile != "" {
+		// client certificates are verified against the authorities current
+		// at each handshake, which also applies to resumed sessions, so that
+		// rotated authorities are honoured
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			reloader.reload()
+			c := config.Clone()
+			c.GetConfigForClient = nil
+			c.ClientCAs = reloader.clientCAs()
+			return c, nil
+		}
+	}
+	return config, nil
+}
+
+// GetCertificate returns the current certificate for a tls handshake
+func (c *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.reload()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+// clientCAs returns the current client certificate authorities
+func (c *certReloader) clientCAs() *x509.CertPool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.pool
+}
+
+// reload loads the files again when they were modified since they were last
+// loaded, continuing to serve the previous certificate when they are invalid
+func (c *certReloader) reload() {
+	c.mu.Lock()
+	if time.Since(c.checked) < tlsReloadInterval {
+		c.mu.Unlock()
+		return
+	}
+	c.checked = time.Now()
+	loaded := c.modTime
+	c.mu.Unlock()
+
+	if modTime, err := c.latestModTime(); err != nil || !modTime.After(loaded) {
+		return
+	}
+	if err := c.load(); err != nil {
+		log.Println("failed to reload tls certificates: ", err)
+		return
+	}
+	log.Println("reloaded tls certificates")
+}
+
+func (c *certReloader) load() error {
+	modTime, err := c.latestModTime()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to load tls certificate")
+	}
+
+	var pool *x509.CertPool
+	if c.caFile != "" {
+		pem, err := ioutil.ReadFile(c.caFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to read client ca")
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("no certificates found in client ca " + c.caFile)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cert, c.pool, c.modTime, c.checked = &cert, pool, modTime, time.Now()
+	return nil
+}
+
+// latestModTime returns the most recent modification time of the files
+func (c *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{c.certFile, c.keyFile, c.caFile} {
+		if name == "" {
+			continue
+		}
+		fi, err := os.Stat(name)
+		if err != nil {
+			return latest, errors.Wrap(err, "failed to stat "+name)
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,271 @@
+package actions
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for the tls tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("failed to generate key: ", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "mac2vnd test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal("failed to create ca: ", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns the pem encoded certificate and key for the given serial
+func (ca *testCA) issue(t *testing.T, serial int64, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal("failed to generate key: ", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "mac2vnd"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal("failed to create certificate: ", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal("failed to marshal key: ", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, b []byte, modTime time.Time) {
+	if err := ioutil.WriteFile(path, b, 0600); err != nil {
+		t.Fatal("failed to write file: ", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal("failed to set modification time: ", err)
+	}
+}
+
+func TestTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mac2vnd")
+	if err != nil {
+		t.Fatal("failed to create directory: ", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCA(t)
+	certFile, keyFile, caFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	cert, key := ca.issue(t, 2, x509.ExtKeyUsageServerAuth)
+	modTime := time.Now().Add(-time.Minute)
+	writeFile(t, certFile, cert, modTime)
+	writeFile(t, keyFile, key, modTime)
+	writeFile(t, caFile, ca.pem, modTime)
+
+	clientCert, clientKey := ca.issue(t, 3, x509.ExtKeyUsageClientAuth)
+	clientPair, err := tls.X509KeyPair(clientCert, clientKey)
+	if err != nil {
+		t.Fatal("failed to load client certificate: ", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	start := func(t *testing.T, caFile, minVersion string) (string, func()) {
+		config, err := newTLSConfig(certFile, keyFile, caFile, minVersion)
+		if err != nil {
+			t.Fatal("failed to configure tls: ", err)
+		}
+
+		ln, err := listen("127.0.0.1:0")
+		if err != nil {
+			t.Fatal("failed to listen: ", err)
+		}
+
+		srv := newServer()
+		srv.TLSConfig = config
+		stop := make(chan os.Signal, 1)
+		done := make(chan error, 1)
+		go func() {
+			done <- serve(srv, ln, stop)
+		}()
+
+		return "https://" + ln.Addr().String() + "/v1/lookup/84:38:35:77:aa:52", func() {
+			stop <- syscall.SIGTERM
+			if err := <-done; err != nil {
+				t.Error("failed to shutdown: ", err)
+			}
+		}
+	}
+
+	get := func(url string, config *tls.Config) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: config, ForceAttemptHTTP2: true}}
+		resp, err := client.Get(url)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+
+	t.Run("TLS", func(t *testing.T) {
+		url, stop := start(t, "", "1.2")
+		defer stop()
+
+		resp, err := get(url, &tls.Config{RootCAs: roots})
+		if err != nil {
+			t.Fatal("failed to request over tls: ", err)
+		}
+		if resp.StatusCode != http.StatusOK || resp.TLS == nil || resp.ProtoMajor != 2 {
+			t.Errorf("received unexpected response: %v %v", resp.Status, resp.Proto)
+		}
+	})
+
+	t.Run("Minimum Version", func(t *testing.T) {
+		url, stop := start(t, "", "1.3")
+		defer stop()
+
+		if _, err := get(url, &tls.Config{RootCAs: roots, MaxVersion: tls.VersionTLS12}); err == nil {
+			t.Error("expected a tls 1.2 handshake to be rejected")
+		}
+		if _, err := get(url, &tls.Config{RootCAs: roots}); err != nil {
+			t.Error("failed to request over tls 1.3: ", err)
+		}
+	})
+
+	t.Run("Mutual TLS", func(t *testing.T) {
+		url, stop := start(t, caFile, "1.2")
+		defer stop()
+
+		if _, err := get(url, &tls.Config{RootCAs: roots}); err == nil {
+			t.Error("expected a client without a certificate to be rejected")
+		}
+		resp, err := get(url, &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{clientPair}})
+		if err != nil {
+			t.Fatal("failed to request with a client certificate: ", err)
+		}
+		if resp.ProtoMajor != 2 {
+			t.Errorf("expected http/2 to be negotiated, received %s", resp.Proto)
+		}
+	})
+
+	t.Run("Rotated Authority", func(t *testing.T) {
+		defer func(interval time.Duration) {
+			tlsReloadInterval = interval
+			writeFile(t, caFile, ca.pem, modTime)
+		}(tlsReloadInterval)
+		tlsReloadInterval = 0
+
+		url, stop := start(t, caFile, "1.2")
+		defer stop()
+
+		config := &tls.Config{
+			RootCAs:            roots,
+			Certificates:       []tls.Certificate{clientPair},
+			ClientSessionCache: tls.NewLRUClientSessionCache(1),
+			MaxVersion:         tls.VersionTLS12,
+		}
+		if _, err := get(url, config); err != nil {
+			t.Fatal("failed to request with a client certificate: ", err)
+		}
+		if resp, err := get(url, config); err != nil || !resp.TLS.DidResume {
+			t.Fatal("expected the session to be resumed: ", err)
+		}
+
+		writeFile(t, caFile, newTestCA(t).pem, time.Now().Add(time.Second))
+		if _, err := get(url, config); err == nil {
+			t.Error("expected a session established under a removed authority to be rejected")
+		}
+	})
+
+	t.Run("Reload", func(t *testing.T) {
+		defer func(interval time.Duration) {
+			tlsReloadInterval = interval
+		}(tlsReloadInterval)
+		tlsReloadInterval = 0
+
+		url, stop := start(t, "", "1.2")
+		defer stop()
+
+		serial := func() int64 {
+			resp, err := get(url, &tls.Config{RootCAs: roots})
+			if err != nil {
+				t.Fatal("failed to request over tls: ", err)
+			}
+			return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+		}
+		if actual := serial(); actual != 2 {
+			t.Fatalf("received certificate %d; expected 2", actual)
+		}
+
+		writeFile(t, keyFile, []byte("rotating"), time.Now())
+		if actual := serial(); actual != 2 {
+			t.Errorf("expected the previous certificate while rotation is incomplete, received %d", actual)
+		}
+
+		cert, key := ca.issue(t, 4, x509.ExtKeyUsageServerAuth)
+		writeFile(t, certFile, cert, time.Now().Add(time.Second))
+		writeFile(t, keyFile, key, time.Now().Add(time.Second))
+		if actual := serial(); actual != 4 {
+			t.Errorf("expected the rotated certificate, received %d", actual)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if _, err := newTLSConfig(certFile, keyFile, "", "1.4"); err == nil {
+			t.Error("expected an unsupported version to fail")
+		}
+		if _, err := newTLSConfig(certFile, "", "", "1.2"); err == nil {
+			t.Error("expected a missing key to fail")
+		}
+		if _, err := newTLSConfig(certFile, keyFile, certFile+".missing", "1.2"); err == nil {
+			t.Error("expected a missing client ca to fail")
+		}
+
+		defer func() {
+			tlsClientCA = ""
+		}()
+		tlsClientCA = caFile
+		if err := serveAction(nil); err == nil {
+			t.Error("expected a client ca without a certificate and key to fail")
+		}
+	})
+}
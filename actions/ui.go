@@ -0,0 +1,37 @@
+package actions
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+const uiPath = "/ui/"
+
+// uiFiles are the static assets of the interactive lookup page
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// uiHandler serves the embedded lookup page, which calls the v1 api from the
+// browser and so references no external assets
+func uiHandler() http.Handler {
+	assets, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err)
+	}
+
+	files := http.StripPrefix(uiPath, http.FileServer(http.FS(assets)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", http.MethodGet+", "+http.MethodHead)
+			writeProblem(w, newProblem(r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "the web interface only supports GET"))
+			return
+		}
+
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Cache-Control", "no-cache")
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,200 @@
+(function () {
+  "use strict";
+
+  var keyStorage = "mac2vendor.apiKey";
+  var results = [];
+
+  function $(id) {
+    return document.getElementById(id);
+  }
+
+  // api resolves a path relative to the service, so the page may be hosted
+  // behind a proxy under any prefix
+  function api(path) {
+    return new URL("../" + path, window.location.href).toString();
+  }
+
+  function request(path, options) {
+    options = options || {};
+    options.headers = options.headers || {};
+    var key = window.localStorage.getItem(keyStorage);
+    if (key) {
+      options.headers["X-API-Key"] = key;
+    }
+
+    return fetch(api(path), options).then(function (res) {
+      return res.json().catch(function () {
+        return {};
+      }).then(function (body) {
+        if (res.status === 401) {
+          $("credentials").hidden = false;
+        }
+        if (!res.ok) {
+          throw new Error(body.detail || body.title || res.statusText);
+        }
+        return body;
+      });
+    });
+  }
+
+  function status(id, message, failed) {
+    var el = $(id);
+    el.textContent = message;
+    el.classList.toggle("error", !!failed);
+  }
+
+  function cell(row, text, className) {
+    var td = row.insertCell();
+    td.textContent = text;
+    if (className) {
+      td.className = className;
+    }
+    return td;
+  }
+
+  function flags(address) {
+    var names = [];
+    if (address.flags.multicast) names.push(address.flags.broadcast ? "broadcast" : "multicast");
+    if (address.flags.local) names.push("locally administered");
+    if (address.flags.virtual) names.push(address.flags.hypervisor || "virtual");
+    return names;
+  }
+
+  function render() {
+    var tbody = $("results").tBodies[0];
+    tbody.textContent = "";
+    results.forEach(function (res) {
+      var row = tbody.insertRow();
+      cell(row, res.input, "mono");
+      if (res.error) {
+        row.className = "invalid";
+        var td = cell(row, res.error.detail || res.error.title);
+        td.colSpan = 4;
+        return;
+      }
+
+      var addr = res.address;
+      row.className = addr.vendor ? "" : "unknown";
+      cell(row, addr.normalized, "mono");
+      cell(row, addr.prefix, "mono");
+      cell(row, addr.vendor ? addr.vendor.name : "unknown", "vendor");
+      var td = row.insertCell();
+      flags(addr).forEach(function (name) {
+        var span = document.createElement("span");
+        span.className = "flag";
+        span.textContent = name;
+        td.appendChild(span);
+      });
+    });
+    $("results").hidden = results.length === 0;
+    $("download").disabled = results.length === 0;
+  }
+
+  function lookup(event) {
+    event.preventDefault();
+    var inputs = $("addresses").value.split(/\r?\n/).map(function (line) {
+      return line.trim();
+    }).filter(function (line) {
+      return line !== "" && line.charAt(0) !== "#";
+    });
+    if (inputs.length === 0) {
+      status("lookup-status", "Enter at least one MAC address.", true);
+      return;
+    }
+
+    status("lookup-status", "Resolving " + inputs.length + " addresses...");
+    request("v1/lookup", {
+      method: "POST",
+      headers: {"Content-Type": "application/json"},
+      body: JSON.stringify(inputs)
+    }).then(function (body) {
+      results = body;
+      render();
+      var resolved = results.filter(function (res) {
+        return res.address && res.address.vendor;
+      }).length;
+      status("lookup-status", resolved + " of " + results.length + " addresses resolved.");
+    }).catch(function (err) {
+      status("lookup-status", err.message, true);
+    });
+  }
+
+  function search(event) {
+    event.preventDefault();
+    var query = $("query").value.trim();
+    if (query === "") {
+      return;
+    }
+
+    status("search-status", "Searching...");
+    request("v1/search?limit=100&q=" + encodeURIComponent(query)).then(function (body) {
+      var tbody = $("vendors").tBodies[0];
+      tbody.textContent = "";
+      body.vendors.forEach(function (vendor) {
+        var row = tbody.insertRow();
+        cell(row, vendor.prefix, "mono");
+        cell(row, vendor.name);
+      });
+      $("vendors").hidden = body.vendors.length === 0;
+      var shown = body.vendors.length < body.total ? ", showing the first " + body.vendors.length : "";
+      status("search-status", body.total + " matching prefixes" + shown + ".");
+    }).catch(function (err) {
+      status("search-status", err.message, true);
+    });
+  }
+
+  function csvField(value) {
+    value = value === undefined || value === null ? "" : String(value);
+    return /[",\r\n]/.test(value) ? '"' + value.replace(/"/g, '""') + '"' : value;
+  }
+
+  function download() {
+    var lines = [["input", "mac", "prefix", "vendor", "multicast", "broadcast", "local", "virtual", "hypervisor", "error"]];
+    results.forEach(function (res) {
+      var addr = res.address;
+      if (!addr) {
+        lines.push([res.input, "", "", "", "", "", "", "", "", res.error.detail || res.error.title]);
+        return;
+      }
+      lines.push([res.input, addr.normalized, addr.prefix, addr.vendor ? addr.vendor.name : "",
+        addr.flags.multicast, addr.flags.broadcast, addr.flags.local, addr.flags.virtual, addr.flags.hypervisor || "", ""]);
+    });
+
+    var csv = lines.map(function (line) {
+      return line.map(csvField).join(",");
+    }).join("\r\n") + "\r\n";
+    var link = document.createElement("a");
+    link.href = URL.createObjectURL(new Blob([csv], {type: "text/csv"}));
+    link.download = "mac2vendor.csv";
+    document.body.appendChild(link);
+    link.click();
+    document.body.removeChild(link);
+    URL.revokeObjectURL(link.href);
+  }
+
+  function saveKey(event) {
+    event.preventDefault();
+    window.localStorage.setItem(keyStorage, $("api-key").value.trim());
+    $("credentials").hidden = true;
+    describe();
+  }
+
+  function describe() {
+    request("v1/info").then(function (info) {
+      $("info").textContent = info.entries.toLocaleString() + " prefixes, updated " +
+        new Date(info.updated).toLocaleDateString();
+    }).catch(function () {});
+  }
+
+  $("lookup").addEventListener("submit", lookup);
+  $("search").addEventListener("submit", search);
+  $("key").addEventListener("submit", saveKey);
+  $("download").addEventListener("click", download);
+  $("clear").addEventListener("click", function () {
+    $("addresses").value = "";
+    results = [];
+    render();
+    status("lookup-status", "");
+  });
+  describe();
+})();
@@ -0,0 +1,51 @@
+package actions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestUI(t *testing.T) {
+	router := newRouter()
+	get := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		guard(router).ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	w := get(http.MethodGet, uiPath)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("received unexpected response: %v %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if w.Header().Get("Content-Security-Policy") == "" {
+		t.Error("expected a content security policy")
+	}
+
+	// every asset referenced by the page is embedded and none are external
+	refs := regexp.MustCompile(`(?:src|href)="([^"]+)"`).FindAllStringSubmatch(w.Body.String(), -1)
+	if len(refs) == 0 {
+		t.Fatal("expected the page to reference its assets")
+	}
+	for _, ref := range refs {
+		if strings.Contains(ref[1], "//") {
+			t.Errorf("expected %s to be served from the binary", ref[1])
+			continue
+		}
+		if asset := get(http.MethodGet, uiPath+ref[1]); asset.Code != http.StatusOK || asset.Body.Len() == 0 {
+			t.Errorf("failed to serve %s: %v", ref[1], asset.Code)
+		}
+	}
+
+	if w := get(http.MethodGet, strings.TrimSuffix(uiPath, "/")); w.Code/100 != 3 || w.Header().Get("Location") != uiPath {
+		t.Errorf("expected a redirect to the page, received %v %s", w.Code, w.Header().Get("Location"))
+	}
+	if w := get(http.MethodPost, uiPath); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("received unexpected status code: %v", w.Code)
+	}
+	if w := get(http.MethodGet, uiPath+"missing.js"); w.Code != http.StatusNotFound {
+		t.Errorf("received unexpected status code: %v", w.Code)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"regexp"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/pkg/errors"
 	"gopkg.in/urfave/cli.v1"
@@ -157,7 +158,10 @@ func generateMapping(mapping map[string]string) error {
 
 	log.Println("executing template...")
 	buffer := new(bytes.Buffer)
-	err = t.Execute(buffer, mapping)
+	err = t.Execute(buffer, struct {
+		Updated int64
+		Mapping map[string]string
+	}{time.Now().Unix(), mapping})
 	if err != nil {
 		return errors.Wrap(err, "failed to execute template")
 	}
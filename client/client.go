@@ -0,0 +1,379 @@
+// Package client resolves mac addresses against a remote mac2vendor web
+// service, implementing the same interface as the in-process database
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	m2v "github.com/n3integration/mac2vendor"
+	"github.com/n3integration/mac2vendor/internal/lru"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultRetries is the number of times failed requests are retried
+	DefaultRetries = 3
+	// DefaultBackoff is the delay before the first retry, which doubles with
+	// each subsequent retry
+	DefaultBackoff = 100 * time.Millisecond
+	// DefaultCacheSize is the number of addresses cached
+	DefaultCacheSize = 1024
+	// DefaultBatchSize bounds the addresses sent in a single batch request,
+	// matching the default limit of the service
+	DefaultBatchSize = 1000
+
+	apiKeyHeader = "X-API-Key"
+	// codeBatchTooLarge is the problem reported for batches exceeding the
+	// limit of the service
+	codeBatchTooLarge = "batch_too_large"
+)
+
+var _ m2v.Service = (*Client)(nil)
+
+// Client is a mac2vendor web service client
+type Client struct {
+	base      *url.URL
+	http      *http.Client
+	apiKey    string
+	retries   int
+	backoff   time.Duration
+	batchSize int
+	cache     *lru.Cache
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the http client with which requests are sent
+func WithHTTPClient(c *http.Client) Option {
+	return func(client *Client) {
+		client.http = c
+	}
+}
+
+// WithAPIKey sets the api key presented to the service
+func WithAPIKey(key string) Option {
+	return func(client *Client) {
+		client.apiKey = key
+	}
+}
+
+// WithRetries sets the number of times requests failing with network errors,
+// rate limits or unavailability are retried, and the delay before the first
+// retry
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(client *Client) {
+		client.retries, client.backoff = retries, backoff
+	}
+}
+
+// WithBatchSize sets the number of addresses sent in a single batch request,
+// which should not exceed the limit of the service. Batches the service
+// refuses as too large are split regardless.
+func WithBatchSize(size int) Option {
+	return func(client *Client) {
+		client.batchSize = size
+	}
+}
+
+// WithCacheSize sets the number of addresses whose vendors are cached, where
+// zero disables caching. Vendors are cached by address rather than prefix as
+// the service may override the vendor of individual addresses
+func WithCacheSize(size int) Option {
+	return func(client *Client) {
+		client.cache = nil
+		if size > 0 {
+			client.cache = lru.New(size)
+		}
+	}
+}
+
+// New initializes a client of the service at the base url
+func New(baseURL string, opts ...Option) (*Client, error) {
+	base, err := url.Parse(strings.TrimSuffix(baseURL, "/") + "/")
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid base url")
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, errors.Errorf("unsupported base url scheme: %s", base.Scheme)
+	}
+
+	c := &Client{
+		base:      base,
+		http:      http.DefaultClient,
+		retries:   DefaultRetries,
+		backoff:   DefaultBackoff,
+		batchSize: DefaultBatchSize,
+		cache:     lru.New(DefaultCacheSize),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.batchSize < 1 {
+		return nil, errors.Errorf("invalid batch size: %d", c.batchSize)
+	}
+	return c, nil
+}
+
+// Error is a problem reported by the service
+type Error struct {
+	Status int    `json:"status"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	Code   string `json:"code"`
+}
+
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("mac2vendor: %s (%d %s)", e.Detail, e.Status, e.Code)
+	}
+	return fmt.Sprintf("mac2vendor: %s (%d %s)", e.Title, e.Status, e.Code)
+}
+
+type address struct {
+	Vendor *struct {
+		Name string `json:"name"`
+	} `json:"vendor"`
+}
+
+func (a *address) vendor() string {
+	if a == nil || a.Vendor == nil {
+		return ""
+	}
+	return a.Vendor.Name
+}
+
+// Lookup resolves the address to its vendor, which is empty when the prefix
+// is not registered
+func (c *Client) Lookup(ctx context.Context, mac string) (string, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return "", err
+	}
+
+	if vendor, ok := c.cache.Get(hw.String()); ok {
+		return vendor.(string), nil
+	}
+
+	var addr address
+	if err := c.do(ctx, http.MethodGet, "v1/lookup/"+url.PathEscape(hw.String()), nil, &addr); err != nil {
+		return "", err
+	}
+	c.cache.Add(hw.String(), addr.vendor())
+	return addr.vendor(), nil
+}
+
+// LookupBatch resolves each address, returning the results in order. Invalid
+// addresses are reported in their result rather than failing the batch, while
+// batches the service fails or answers incompletely return an error
+func (c *Client) LookupBatch(ctx context.Context, macs []string) ([]m2v.Result, error) {
+	results := make([]m2v.Result, len(macs))
+	var pending []int
+	for i, mac := range macs {
+		results[i].MAC = mac
+		hw, err := net.ParseMAC(mac)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if vendor, ok := c.cache.Get(hw.String()); ok {
+			results[i].Vendor = vendor.(string)
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	size := c.batchSize
+	for len(pending) > 0 {
+		n := len(pending)
+		if n > size {
+			n = size
+		}
+		chunk := pending[:n]
+		pending = pending[n:]
+
+		inputs := make([]string, len(chunk))
+		for j, i := range chunk {
+			inputs[j] = macs[i]
+		}
+		body, err := json.Marshal(inputs)
+		if err != nil {
+			return nil, err
+		}
+
+		var batch []struct {
+			Index   int      `json:"index"`
+			Input   string   `json:"input"`
+			Address *address `json:"address"`
+			Error   *Error   `json:"error"`
+		}
+		err = c.do(ctx, http.MethodPost, "v1/lookup", body, &batch)
+		if e, ok := err.(*Error); ok && e.Status == http.StatusRequestEntityTooLarge && n > 1 {
+			// the service accepts smaller batches than requested
+			size = (n + 1) / 2
+			pending = append(append([]int{}, chunk...), pending...)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		// every address of the chunk must be answered, or refused as beyond
+		// the limit of the service, lest missing results pass for unknown
+		// vendors
+		received := n
+		resolved := make([]bool, n)
+		for _, res := range batch {
+			if res.Index < 0 || res.Index >= len(chunk) {
+				return nil, errors.Errorf("mac2vendor: batch result index %d out of range", res.Index)
+			}
+			i := chunk[res.Index]
+			if res.Error != nil && res.Input != macs[i] {
+				if res.Error.Code != codeBatchTooLarge || res.Index == 0 {
+					// the service failed the batch after streaming the
+					// results preceding the failure
+					return nil, res.Error
+				}
+				// the service streamed the results up to its limit before
+				// refusing the rest of the batch
+				size, received = res.Index, res.Index
+				pending = append(append([]int{}, chunk[res.Index:]...), pending...)
+				break
+			}
+			resolved[res.Index] = true
+			if res.Error != nil {
+				results[i].Err = res.Error
+				continue
+			}
+			results[i].Vendor = res.Address.vendor()
+			hw, _ := net.ParseMAC(macs[i])
+			c.cache.Add(hw.String(), results[i].Vendor)
+		}
+		for j := 0; j < received; j++ {
+			if !resolved[j] {
+				return nil, errors.Errorf("mac2vendor: batch response is missing the result of %s", macs[chunk[j]])
+			}
+		}
+	}
+	return results, nil
+}
+
+// Search returns up to limit entries matching the query, or all of them when
+// limit is zero
+func (c *Client) Search(ctx context.Context, query string, limit int) ([]m2v.Entry, error) {
+	params := url.Values{"q": {query}, "limit": {strconv.Itoa(limit)}}
+
+	var res struct {
+		Vendors []struct {
+			Name   string `json:"name"`
+			Prefix string `json:"prefix"`
+		} `json:"vendors"`
+	}
+	if err := c.do(ctx, http.MethodGet, "v1/search?"+params.Encode(), nil, &res); err != nil {
+		return nil, err
+	}
+
+	entries := make([]m2v.Entry, 0, len(res.Vendors))
+	for _, v := range res.Vendors {
+		entries = append(entries, m2v.Entry{Prefix: v.Prefix, Vendor: v.Name})
+	}
+	return entries, nil
+}
+
+// Info describes the vendor database of the service
+func (c *Client) Info(ctx context.Context) (*m2v.Info, error) {
+	var info m2v.Info
+	if err := c.do(ctx, http.MethodGet, "v1/info", nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// do sends the request, retrying network errors, rate limits and
+// unavailability with exponential backoff, and decodes the response into v
+func (c *Client) do(ctx context.Context, method, path string, body []byte, v interface{}) error {
+	ref, err := url.Parse(path)
+	if err != nil {
+		return err
+	}
+	target := c.base.ResolveReference(ref).String()
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		retry, wait, err := c.send(ctx, method, target, body, v)
+		if err == nil || !retry || attempt >= c.retries {
+			return err
+		}
+
+		if wait < backoff {
+			wait = backoff
+		}
+		backoff *= 2
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// send performs a single attempt of the request, reporting whether a failure
+// may be retried and how long the service asked clients to wait
+func (c *Client) send(ctx context.Context, method, target string, body []byte, v interface{}) (bool, time.Duration, error) {
+	req, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return false, 0, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, 0, ctx.Err()
+		}
+		return true, 0, errors.Wrap(err, "mac2vendor: request failed")
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		problem := &Error{Status: res.StatusCode, Title: http.StatusText(res.StatusCode)}
+		b, _ := ioutil.ReadAll(io.LimitReader(res.Body, 64<<10))
+		json.Unmarshal(b, problem)
+
+		switch res.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			wait := time.Duration(0)
+			if s, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+				wait = time.Duration(s) * time.Second
+			}
+			return true, wait, problem
+		default:
+			return false, 0, problem
+		}
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return true, 0, errors.Wrap(err, "mac2vendor: failed to decode response")
+	}
+	return false, 0, nil
+}
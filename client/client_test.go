@@ -0,0 +1,228 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	var requests, failures, resolved int32
+	maxBatch := int32(1000)
+	// truncate ends batches at the index, reporting a failure when failed
+	truncate, failed := int32(-1), int32(0)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/lookup/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(apiKeyHeader) != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status": 401, "code": "unauthorized", "detail": "the api key is not valid"}`))
+			return
+		}
+		if atomic.AddInt32(&failures, -1) >= 0 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "52:54:00:12:34:56") {
+			w.Write([]byte(`{"mac": "52:54:00:12:34:56", "vendor": null}`))
+			return
+		}
+		w.Write([]byte(`{"mac": "84:38:35:77:aa:52", "vendor": {"name": "Apple, Inc.", "prefix": "84:38:35"}}`))
+	})
+	mux.HandleFunc("/v1/lookup", func(w http.ResponseWriter, r *http.Request) {
+		var inputs []string
+		json.NewDecoder(r.Body).Decode(&inputs)
+		if limit := int(atomic.LoadInt32(&maxBatch)); len(inputs) > 2*limit {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			w.Write([]byte(`{"status": 413, "code": "batch_too_large"}`))
+			return
+		}
+		results := make([]map[string]interface{}, 0)
+		for i, mac := range inputs {
+			if i == int(atomic.LoadInt32(&maxBatch)) {
+				// larger batches are refused after their first results
+				results = append(results, map[string]interface{}{"index": i, "input": "", "error": map[string]interface{}{"status": 413, "code": "batch_too_large"}})
+				break
+			}
+			if i == int(atomic.LoadInt32(&truncate)) {
+				if atomic.LoadInt32(&failed) != 0 {
+					results = append(results, map[string]interface{}{"index": i, "input": "", "error": map[string]interface{}{"status": 400, "code": "invalid_body"}})
+				}
+				break
+			}
+			atomic.AddInt32(&resolved, 1)
+			res := map[string]interface{}{"index": i, "input": mac}
+			if strings.HasPrefix(mac, "00:00:0c") {
+				res["address"] = map[string]interface{}{"vendor": map[string]string{"name": "Cisco Systems, Inc"}}
+			} else if strings.HasPrefix(mac, "84:38:35") {
+				res["address"] = map[string]interface{}{"vendor": map[string]string{"name": "Apple, Inc."}}
+			} else {
+				res["address"] = map[string]interface{}{"vendor": nil}
+			}
+			results = append(results, res)
+		}
+		json.NewEncoder(w).Encode(results)
+	})
+	mux.HandleFunc("/v1/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("q") != "apple" || r.URL.Query().Get("limit") != "2" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"vendors": [{"name": "Apple, Inc.", "prefix": "00:03:93"}, {"name": "Apple, Inc.", "prefix": "00:05:02"}]}`))
+	})
+	mux.HandleFunc("/v1/info", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"entries": 2, "updated": "2019-03-02T00:00:00Z", "version": "abc"}`))
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		mux.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	c, err := New(srv.URL, WithAPIKey("secret"), WithRetries(2, time.Millisecond), WithCacheSize(2))
+	if err != nil {
+		t.Fatal("failed to initialize client: ", err)
+	}
+	ctx := context.Background()
+
+	t.Run("Lookup", func(t *testing.T) {
+		atomic.StoreInt32(&requests, 0)
+		atomic.StoreInt32(&failures, 2)
+		vendor, err := c.Lookup(ctx, "84-38-35-77-AA-52")
+		if err != nil || vendor != "Apple, Inc." {
+			t.Fatalf("received unexpected vendor: %q %v", vendor, err)
+		}
+		if n := atomic.LoadInt32(&requests); n != 3 {
+			t.Errorf("sent %d requests; expected 2 retries", n)
+		}
+
+		vendor, err = c.Lookup(ctx, "84:38:35:77:aa:52")
+		if err != nil || vendor != "Apple, Inc." || atomic.LoadInt32(&requests) != 3 {
+			t.Errorf("expected the address to be cached: %q %v", vendor, err)
+		}
+		if _, err := c.Lookup(ctx, "84:38:35:00:00:01"); err != nil || atomic.LoadInt32(&requests) != 4 {
+			t.Errorf("expected other addresses of the prefix to be requested: %v", err)
+		}
+
+		if vendor, err := c.Lookup(ctx, "52:54:00:12:34:56"); err != nil || vendor != "" {
+			t.Errorf("received unexpected vendor for an unregistered prefix: %q %v", vendor, err)
+		}
+		if _, err := c.Lookup(ctx, "not-a-mac"); err == nil {
+			t.Error("expected an invalid address to fail")
+		}
+	})
+
+	t.Run("Retries Exhausted", func(t *testing.T) {
+		atomic.StoreInt32(&failures, 3)
+		defer atomic.StoreInt32(&failures, 0)
+		_, err := c.Lookup(ctx, "00:00:0c:00:00:01")
+		if e, ok := err.(*Error); !ok || e.Status != http.StatusServiceUnavailable {
+			t.Errorf("expected the last failure, received %v", err)
+		}
+	})
+
+	t.Run("Problem", func(t *testing.T) {
+		anonymous, _ := New(srv.URL, WithRetries(0, 0))
+		_, err := anonymous.Lookup(ctx, "84:38:35:77:aa:52")
+		if e, ok := err.(*Error); !ok || e.Code != "unauthorized" || e.Status != http.StatusUnauthorized {
+			t.Errorf("expected the problem to be decoded, received %v", err)
+		}
+	})
+
+	t.Run("LookupBatch", func(t *testing.T) {
+		results, err := c.LookupBatch(ctx, []string{"00:00:0c:00:00:01", "not-a-mac", "84:38:35:77:aa:52", "02:00:00:00:00:01"})
+		if err != nil {
+			t.Fatal("failed to lookup batch: ", err)
+		}
+		if results[0].Vendor != "Cisco Systems, Inc" || results[1].Err == nil || results[2].Vendor != "Apple, Inc." || results[3].Vendor != "" {
+			t.Errorf("received unexpected results: %+v", results)
+		}
+	})
+
+	t.Run("Batch Too Large", func(t *testing.T) {
+		atomic.StoreInt32(&maxBatch, 2)
+		atomic.StoreInt32(&resolved, 0)
+		defer atomic.StoreInt32(&maxBatch, 1000)
+
+		uncached, _ := New(srv.URL, WithAPIKey("secret"), WithBatchSize(10), WithCacheSize(0))
+		macs := []string{
+			"00:00:0c:00:00:01", "00:00:0c:00:00:02", "00:00:0c:00:00:03", "02:00:00:00:00:01",
+			"00:00:0c:00:00:04", "00:00:0c:00:00:05", "00:00:0c:00:00:06",
+		}
+		results, err := uncached.LookupBatch(ctx, macs)
+		if err != nil {
+			t.Fatal("failed to lookup batch: ", err)
+		}
+		for i, res := range results {
+			if res.Err != nil || res.MAC != macs[i] || (res.Vendor == "") != (i == 3) {
+				t.Errorf("received unexpected result %d: %+v", i, res)
+			}
+		}
+		if n := atomic.LoadInt32(&resolved); n != int32(len(macs)) {
+			t.Errorf("resolved %d addresses; expected each to be resolved once", n)
+		}
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		defer atomic.StoreInt32(&truncate, -1)
+		atomic.StoreInt32(&truncate, 1)
+
+		uncached, _ := New(srv.URL, WithAPIKey("secret"), WithCacheSize(0))
+		macs := []string{"00:00:0c:00:00:01", "02:00:00:00:00:01", "00:00:0c:00:00:02"}
+		if _, err := uncached.LookupBatch(ctx, macs); err == nil {
+			t.Error("expected missing results to fail the batch")
+		}
+
+		atomic.StoreInt32(&failed, 1)
+		defer atomic.StoreInt32(&failed, 0)
+		if _, err := uncached.LookupBatch(ctx, macs); err == nil || !strings.Contains(err.Error(), "invalid_body") {
+			t.Errorf("expected the failure of the batch to be returned, received %v", err)
+		}
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		entries, err := c.Search(ctx, "apple", 2)
+		if err != nil || len(entries) != 2 || entries[1].Prefix != "00:05:02" {
+			t.Errorf("received unexpected entries: %+v %v", entries, err)
+		}
+	})
+
+	t.Run("Info", func(t *testing.T) {
+		info, err := c.Info(ctx)
+		if err != nil || info.Entries != 2 || info.Version != "abc" || info.Updated.Year() != 2019 {
+			t.Errorf("received unexpected info: %+v %v", info, err)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		atomic.StoreInt32(&failures, 10)
+		defer atomic.StoreInt32(&failures, 0)
+		slow, _ := New(srv.URL, WithAPIKey("secret"), WithRetries(5, time.Hour))
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if _, err := slow.Lookup(ctx, "00:00:0c:00:00:01"); err != context.DeadlineExceeded {
+			t.Errorf("expected the deadline to interrupt retries, received %v", err)
+		}
+	})
+}
+
+func TestNew(t *testing.T) {
+	for _, base := range []string{"ftp://example.com", "://"} {
+		if _, err := New(base); err == nil {
+			t.Errorf("expected %q to be rejected", base)
+		}
+	}
+	if _, err := New("http://" + net.JoinHostPort("127.0.0.1", "9000") + "/api/"); err != nil {
+		t.Error("failed to initialize client: ", err)
+	}
+	if _, err := New("http://127.0.0.1:9000", WithBatchSize(0)); err == nil {
+		t.Error("expected an invalid batch size to be rejected")
+	}
+}
@@ -0,0 +1,226 @@
+package mac2vendor
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ouiPattern matches the registrations of an ieee oui.txt listing
+var ouiPattern = regexp.MustCompile(`^\s*([0-9a-fA-F]{6})\s*\(base 16\)\s*(.+?)\s*$`)
+
+// Database is an in-memory vendor database keyed by address prefix
+type Database struct {
+	entries map[string]string
+	updated time.Time
+
+	versionOnce sync.Once
+	version     string
+}
+
+// NewDatabase initializes a database of the vendors registered for each
+// prefix, which may be written in any of the forms accepted by ParsePrefix
+func NewDatabase(entries map[string]string, updated time.Time) (*Database, error) {
+	db := &Database{entries: make(map[string]string, len(entries)), updated: updated}
+	for key, vendor := range entries {
+		prefix, err := ParsePrefix(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(prefix) != 3 {
+			return nil, errors.Errorf("expected a 3 byte prefix: %s", key)
+		}
+		db.entries[prefix.String()] = vendor
+	}
+	return db, nil
+}
+
+// Open loads the database from an ieee oui.txt listing, or from a file of
+// tab separated prefixes and vendors, taking its modification time as the
+// time at which the database was updated
+func Open(name string) (*Database, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open database")
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to stat database")
+	}
+
+	entries, err := Parse(f)
+	if err != nil {
+		return nil, errors.Wrap(err, name)
+	}
+	return NewDatabase(entries, fi.ModTime().UTC())
+}
+
+// Save writes the database to the named file as tab separated prefixes and
+// vendors, replacing it atomically and preserving the time at which the
+// database was updated as its modification time
+func (db *Database) Save(name string) error {
+	err := save(name, func(w io.Writer) error {
+		for _, e := range db.Entries() {
+			if _, err := fmt.Fprintf(w, "%s\t%s\n", e.Prefix, e.Vendor); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil || db.updated.IsZero() {
+		return err
+	}
+	return errors.Wrap(os.Chtimes(name, db.updated, db.updated), "failed to save "+name)
+}
+
+// save writes a file through a temporary file in the same directory, which
+// is renamed over the original once written in full
+func save(name string, write func(w io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*")
+	if err != nil {
+		return errors.Wrap(err, "failed to save "+name)
+	}
+	defer os.Remove(f.Name())
+
+	w := bufio.NewWriter(f)
+	if err := write(w); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to save "+name)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to save "+name)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "failed to save "+name)
+	}
+	return errors.Wrap(os.Rename(f.Name(), name), "failed to save "+name)
+}
+
+// Parse reads the prefixes and vendors of an ieee oui.txt listing, or of
+// tab separated prefixes and vendors, ignoring any other lines
+func Parse(r io.Reader) (map[string]string, error) {
+	entries := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if parts := ouiPattern.FindStringSubmatch(line); parts != nil {
+			prefix, _ := ParsePrefix(parts[1])
+			entries[prefix.String()] = parts[2]
+			continue
+		}
+
+		parts := strings.SplitN(line, "\t", 2)
+		if len(parts) != 2 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if prefix, err := ParsePrefix(parts[0]); err == nil && len(prefix) == 3 {
+			entries[prefix.String()] = strings.TrimSpace(parts[1])
+		}
+	}
+	return entries, errors.Wrap(scanner.Err(), "failed to read database")
+}
+
+// ParsePrefix parses a 3 byte prefix or a 6 byte address written as hex
+// digits, optionally separated by colons, hyphens or dots
+func ParsePrefix(s string) (net.HardwareAddr, error) {
+	digits := strings.NewReplacer(":", "", "-", "", ".", "").Replace(strings.TrimSpace(s))
+	b, err := hex.DecodeString(digits)
+	if err != nil || (len(b) != 3 && len(b) != 6) {
+		return nil, &net.AddrError{Err: "invalid prefix", Addr: s}
+	}
+	return net.HardwareAddr(b), nil
+}
+
+// Lookup resolves the address to its vendor, which is empty when the prefix
+// is not registered
+func (db *Database) Lookup(_ context.Context, mac string) (string, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return "", err
+	}
+	return db.vendor(hw), nil
+}
+
+func (db *Database) vendor(hw net.HardwareAddr) string {
+	return db.entries[strings.ToLower(hw[:3].String())]
+}
+
+// Len returns the number of prefixes in the database
+func (db *Database) Len() int {
+	return len(db.entries)
+}
+
+// Updated returns the time at which the database was updated
+func (db *Database) Updated() time.Time {
+	return db.updated
+}
+
+// Version returns a digest of the database, which changes whenever any of
+// its entries change
+func (db *Database) Version() string {
+	db.versionOnce.Do(func() {
+		prefixes := make([]string, 0, len(db.entries))
+		for prefix := range db.entries {
+			prefixes = append(prefixes, prefix)
+		}
+		sort.Strings(prefixes)
+
+		h := fnv.New64a()
+		for _, prefix := range prefixes {
+			fmt.Fprintf(h, "%s\t%s\n", prefix, db.entries[prefix])
+		}
+		db.version = fmt.Sprintf("%016x", h.Sum64())
+	})
+	return db.version
+}
+
+// Entries returns every entry of the database, ordered by prefix
+func (db *Database) Entries() []Entry {
+	entries := make([]Entry, 0, len(db.entries))
+	for prefix, vendor := range db.entries {
+		entries = append(entries, Entry{Prefix: prefix, Vendor: vendor})
+	}
+	sortEntries(entries)
+	return entries
+}
+
+// Search returns the entries whose vendor name contains the query, ignoring
+// case, or whose prefix begins with it, ordered by prefix
+func (db *Database) Search(query string) []Entry {
+	query = strings.ToLower(strings.TrimSpace(query))
+	prefix := strings.Replace(query, "-", ":", -1)
+
+	entries := make([]Entry, 0)
+	if query == "" {
+		return entries
+	}
+	for p, vendor := range db.entries {
+		if strings.HasPrefix(p, prefix) || strings.Contains(strings.ToLower(vendor), query) {
+			entries = append(entries, Entry{Prefix: p, Vendor: vendor})
+		}
+	}
+	sortEntries(entries)
+	return entries
+}
+
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Prefix < entries[j].Prefix
+	})
+}
@@ -0,0 +1,28 @@
+package lru
+
+import "testing"
+
+func TestCache(t *testing.T) {
+	c := New(2)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Get("a")
+	c.Add("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Error("expected the recently used entry to be retained")
+	}
+
+	c.Remove("a")
+	if _, ok := c.Get("a"); ok || c.Len() != 1 {
+		t.Error("expected the entry to be removed")
+	}
+
+	var disabled *Cache
+	disabled.Add("a", 1)
+	if _, ok := disabled.Get("a"); ok || disabled.Len() != 0 {
+		t.Error("expected a nil cache to be empty")
+	}
+}
@@ -1,41 +1,100 @@
 package mac2vendor
 
 import (
-	"github.com/pkg/errors"
 	"net"
-	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 var (
 	errCannotResolveType = errors.New("cannot resolve type to mac address")
 	mapping              = make(map[string]string)
+	updated              time.Time
+
+	embedded     *Database
+	embeddedOnce sync.Once
+
+	// current replaces the embedded database as the default once set
+	current atomic.Pointer[Database]
 )
 
+// Embedded returns the database generated into the package
+func Embedded() *Database {
+	embeddedOnce.Do(func() {
+		embedded = &Database{entries: mapping, updated: updated}
+	})
+	return embedded
+}
+
+// Default returns the database consulted by the package level functions,
+// which is the embedded database until replaced with SetDefault
+func Default() *Database {
+	if db := current.Load(); db != nil {
+		return db
+	}
+	return Embedded()
+}
+
+// SetDefault atomically replaces the database consulted by the package level
+// functions, such that lookups in progress complete against the previous one
+func SetDefault(db *Database) {
+	current.Store(db)
+}
+
 // IsLoaded is a predicate to determine whether or not the mapping table was loaded
 func IsLoaded() bool {
-	return len(mapping) > 0
+	return Default().Len() > 0
+}
+
+// Len returns the number of vendor prefixes in the mapping table
+func Len() int {
+	return Default().Len()
+}
+
+// Updated returns the time at which the mapping table was generated
+func Updated() time.Time {
+	return Default().Updated()
+}
+
+// Version returns a digest of the mapping table, which changes whenever any
+// of its entries change
+func Version() string {
+	return Default().Version()
+}
+
+// Entry is a vendor registration for an address prefix, or for a single
+// address in the case of overrides
+type Entry struct {
+	Prefix string
+	Vendor string
+}
+
+// Search returns the entries whose vendor name contains the query, ignoring
+// case, or whose prefix begins with it, ordered by prefix
+func Search(query string) []Entry {
+	return Default().Search(query)
 }
 
 // Lookup resolves the provided MAC address to the registered vendor
 func Lookup(v interface{}) (string, error) {
-	var mac net.HardwareAddr
+	mac, err := parse(v)
+	if err != nil {
+		return "", err
+	}
+	return Default().vendor(mac), nil
+}
+
+// parse converts the provided value into a hardware address
+func parse(v interface{}) (net.HardwareAddr, error) {
 	switch v.(type) {
 	case string:
-		var err error
-		mac, err = net.ParseMAC(v.(string))
-		if err != nil {
-			return "", err
-		}
+		return net.ParseMAC(v.(string))
 	case net.HardwareAddr:
-		mac = v.(net.HardwareAddr)
+		return v.(net.HardwareAddr), nil
 	default:
-		return "", errCannotResolveType
+		return nil, errCannotResolveType
 	}
-
-	prefix := mac[:3].String()
-	if val, ok := mapping[strings.ToLower(prefix)]; ok {
-		return val, nil
-	}
-
-	return "", nil
 }
@@ -1,6 +1,9 @@
 package mac2vendor
 
+import "time"
+
 func init() {
+	updated = time.Unix(1551484800, 0).UTC()
 	mapping["00:00:00"] = "XEROX CORPORATION"
 	mapping["00:00:01"] = "XEROX CORPORATION"
 	mapping["00:00:02"] = "XEROX CORPORATION"
@@ -0,0 +1,140 @@
+package mac2vendor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Overrides resolves locally assigned vendors of individual addresses and
+// prefixes, taking precedence over the registry when chained before it
+type Overrides struct {
+	mu      sync.RWMutex
+	entries map[string]string
+}
+
+// NewOverrides initializes an empty set of overrides
+func NewOverrides() *Overrides {
+	return &Overrides{entries: make(map[string]string)}
+}
+
+// LoadOverrides reads overrides from a file of addresses or prefixes, in any
+// of the forms accepted by ParsePrefix, each followed by whitespace and the
+// vendor, ignoring blank lines and comments
+func LoadOverrides(name string) (*Overrides, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open overrides")
+	}
+	defer f.Close()
+
+	o, err := ReadOverrides(f)
+	return o, errors.Wrap(err, name)
+}
+
+// ReadOverrides reads overrides in the format of LoadOverrides
+func ReadOverrides(r io.Reader) (*Overrides, error) {
+	o := NewOverrides()
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		vendor := strings.TrimSpace(text[len(fields[0]):])
+		if err := o.Set(fields[0], vendor); err != nil {
+			return nil, errors.Wrapf(err, "line %d", line)
+		}
+	}
+	return o, errors.Wrap(scanner.Err(), "failed to read overrides")
+}
+
+// Save writes the overrides to the named file in the format read by
+// LoadOverrides, replacing it atomically
+func (o *Overrides) Save(name string) error {
+	return save(name, func(w io.Writer) error {
+		for _, e := range o.Entries() {
+			if _, err := fmt.Fprintf(w, "%s %s\n", e.Prefix, e.Vendor); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Set assigns the vendor of an address or prefix
+func (o *Overrides) Set(key, vendor string) error {
+	hw, err := ParsePrefix(key)
+	if err != nil {
+		return err
+	}
+	if vendor == "" {
+		return errors.Errorf("a vendor is required for %s", key)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries[hw.String()] = vendor
+	return nil
+}
+
+// Delete removes the override of an address or prefix, reporting whether it
+// was present
+func (o *Overrides) Delete(key string) bool {
+	hw, err := ParsePrefix(key)
+	if err != nil {
+		return false
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	_, ok := o.entries[hw.String()]
+	delete(o.entries, hw.String())
+	return ok
+}
+
+// Entries returns the overrides ordered by address
+func (o *Overrides) Entries() []Entry {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(o.entries))
+	for key, vendor := range o.entries {
+		entries = append(entries, Entry{Prefix: key, Vendor: vendor})
+	}
+	sortEntries(entries)
+	return entries
+}
+
+// Lookup resolves the override of the address, preferring one assigned to
+// the address itself over one assigned to its prefix
+func (o *Overrides) Lookup(_ context.Context, mac string) (string, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return "", err
+	}
+	e, _ := o.Find(hw)
+	return e.Vendor, nil
+}
+
+// Find returns the override applying to the address, which is the one
+// assigned to the address itself or otherwise the one assigned to its prefix
+func (o *Overrides) Find(hw net.HardwareAddr) (Entry, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	for _, key := range []string{hw.String(), hw[:3].String()} {
+		if vendor, ok := o.entries[key]; ok {
+			return Entry{Prefix: key, Vendor: vendor}, true
+		}
+	}
+	return Entry{}, false
+}
@@ -0,0 +1,105 @@
+package mac2vendor
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/n3integration/mac2vendor/internal/lru"
+)
+
+// Resolver resolves mac addresses to their vendor. It is implemented by the
+// embedded and file-backed databases, overrides, remote clients and caches,
+// which may be composed with Chain.
+type Resolver interface {
+	// Lookup resolves the address to its vendor, which is empty when the
+	// resolver does not know it
+	Lookup(ctx context.Context, mac string) (string, error)
+}
+
+var (
+	_ Resolver = (*Database)(nil)
+	_ Resolver = (*Overrides)(nil)
+	_ Resolver = Local()
+)
+
+type chain []Resolver
+
+// Chain consults the resolvers in order, returning the first vendor found.
+// Resolvers that fail are skipped, and the first failure is only returned
+// when no other resolver knows the vendor.
+func Chain(resolvers ...Resolver) Resolver {
+	return chain(resolvers)
+}
+
+func (c chain) Lookup(ctx context.Context, mac string) (string, error) {
+	if _, err := net.ParseMAC(mac); err != nil {
+		return "", err
+	}
+
+	var failure error
+	for _, r := range c {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		vendor, err := r.Lookup(ctx, mac)
+		if err != nil {
+			if failure == nil {
+				failure = err
+			}
+			continue
+		}
+		if vendor != "" {
+			return vendor, nil
+		}
+	}
+	return "", failure
+}
+
+// cached is a resolver decorator caching the vendors of recently resolved
+// addresses
+type cached struct {
+	resolver Resolver
+	ttl      time.Duration
+	cache    *lru.Cache
+}
+
+type cachedVendor struct {
+	vendor  string
+	expires time.Time
+}
+
+// Cached caches up to size of the most recently resolved addresses, including
+// those whose vendor is unknown, for the ttl or indefinitely when it is zero.
+// Failures are not cached.
+func Cached(r Resolver, size int, ttl time.Duration) Resolver {
+	return &cached{resolver: r, ttl: ttl, cache: lru.New(size)}
+}
+
+func (c *cached) Lookup(ctx context.Context, mac string) (string, error) {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return "", err
+	}
+
+	key := hw.String()
+	if v, ok := c.cache.Get(key); ok {
+		entry := v.(cachedVendor)
+		if entry.expires.IsZero() || time.Now().Before(entry.expires) {
+			return entry.vendor, nil
+		}
+		c.cache.Remove(key)
+	}
+
+	vendor, err := c.resolver.Lookup(ctx, mac)
+	if err != nil {
+		return "", err
+	}
+
+	entry := cachedVendor{vendor: vendor}
+	if c.ttl > 0 {
+		entry.expires = time.Now().Add(c.ttl)
+	}
+	c.cache.Add(key, entry)
+	return vendor, nil
+}
@@ -0,0 +1,197 @@
+package mac2vendor
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// resolverFunc adapts a function to the Resolver interface
+type resolverFunc func(ctx context.Context, mac string) (string, error)
+
+func (f resolverFunc) Lookup(ctx context.Context, mac string) (string, error) {
+	return f(ctx, mac)
+}
+
+func TestDatabase(t *testing.T) {
+	listing := strings.Join([]string{
+		"OUI/MA-L                                                    Organization",
+		"company_id                                                  Organization",
+		"",
+		"84-38-35   (hex)		Apple, Inc.",
+		"843835     (base 16)		Apple, Inc.",
+		"				1 Infinite Loop",
+		"00000C     (base 16)		Cisco Systems, Inc",
+		"52:54:00\tQEMU virtual NIC",
+		"# 02:42:00\tcommented",
+	}, "\n")
+
+	entries, err := Parse(strings.NewReader(listing))
+	if err != nil {
+		t.Fatal("failed to parse: ", err)
+	}
+	if len(entries) != 3 || entries["84:38:35"] != "Apple, Inc." || entries["00:00:0c"] != "Cisco Systems, Inc" || entries["52:54:00"] != "QEMU virtual NIC" {
+		t.Errorf("parsed unexpected entries: %v", entries)
+	}
+
+	dir, err := ioutil.TempDir("", "mac2vnd")
+	if err != nil {
+		t.Fatal("failed to create directory: ", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "oui.txt")
+	if err := ioutil.WriteFile(name, []byte(listing), 0600); err != nil {
+		t.Fatal("failed to write database: ", err)
+	}
+	db, err := Open(name)
+	if err != nil {
+		t.Fatal("failed to open database: ", err)
+	}
+	if db.Len() != 3 || db.Updated().IsZero() || db.Version() == Version() {
+		t.Errorf("opened unexpected database: %d entries updated %v version %s", db.Len(), db.Updated(), db.Version())
+	}
+
+	if entries := db.Entries(); len(entries) != 3 || entries[0].Prefix != "00:00:0c" || entries[1].Vendor != "QEMU virtual NIC" {
+		t.Errorf("listed unexpected entries: %+v", entries)
+	}
+
+	vendor, err := db.Lookup(context.Background(), "52-54-00-12-34-56")
+	if err != nil || vendor != "QEMU virtual NIC" {
+		t.Errorf("received unexpected vendor: %q %v", vendor, err)
+	}
+	saved := filepath.Join(dir, "saved.tsv")
+	if err := db.Save(saved); err != nil {
+		t.Fatal("failed to save database: ", err)
+	}
+	if reopened, err := Open(saved); err != nil || reopened.Version() != db.Version() || !reopened.Updated().Equal(db.Updated()) {
+		t.Errorf("expected the saved database to be reopened unchanged: %v", err)
+	}
+
+	if _, err := NewDatabase(map[string]string{"84:38:35:77:aa:52": "Apple, Inc."}, time.Now()); err == nil {
+		t.Error("expected an address to be rejected as a prefix")
+	}
+	if _, err := Open(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected a missing database to fail")
+	}
+}
+
+func TestOverrides(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mac2vnd")
+	if err != nil {
+		t.Fatal("failed to create directory: ", err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "overrides")
+	contents := "# lab equipment\n843835  Apple lab  fleet\n84-38-35-77-AA-52 Build server\n"
+	if err := ioutil.WriteFile(name, []byte(contents), 0600); err != nil {
+		t.Fatal("failed to write overrides: ", err)
+	}
+
+	o, err := LoadOverrides(name)
+	if err != nil {
+		t.Fatal("failed to load overrides: ", err)
+	}
+
+	ctx := context.Background()
+	tests := []struct {
+		mac    string
+		vendor string
+	}{
+		{"84:38:35:77:aa:52", "Build server"},
+		{"84:38:35:00:00:01", "Apple lab  fleet"},
+		{"00:00:0c:00:00:01", ""},
+	}
+	for _, tt := range tests {
+		if vendor, err := o.Lookup(ctx, tt.mac); err != nil || vendor != tt.vendor {
+			t.Errorf("received %q for %s; expected %q", vendor, tt.mac, tt.vendor)
+		}
+	}
+
+	if err := o.Set("not-a-prefix", "vendor"); err == nil {
+		t.Error("expected an invalid prefix to be rejected")
+	}
+	if !o.Delete("84:38:35:77:aa:52") || o.Delete("84:38:35:77:aa:52") {
+		t.Error("expected the override to be deleted once")
+	}
+	if entries := o.Entries(); len(entries) != 1 || entries[0].Prefix != "84:38:35" {
+		t.Errorf("received unexpected entries: %+v", entries)
+	}
+
+	if err := o.Save(name); err != nil {
+		t.Fatal("failed to save overrides: ", err)
+	}
+	if saved, err := LoadOverrides(name); err != nil || len(saved.Entries()) != 1 || saved.Entries()[0].Vendor != "Apple lab  fleet" {
+		t.Errorf("expected the saved overrides to be loaded unchanged: %v", err)
+	}
+
+	if err := ioutil.WriteFile(name, []byte("843835\n"), 0600); err != nil {
+		t.Fatal("failed to write overrides: ", err)
+	}
+	if _, err := LoadOverrides(name); err == nil {
+		t.Error("expected an override without a vendor to fail")
+	}
+}
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	overrides := NewOverrides()
+	overrides.Set("84:38:35:77:aa:52", "Build server")
+	failing := resolverFunc(func(context.Context, string) (string, error) {
+		return "", errors.New("unavailable")
+	})
+
+	r := Chain(overrides, failing, Embedded())
+	tests := []struct {
+		mac    string
+		vendor string
+		err    bool
+	}{
+		{"84:38:35:77:aa:52", "Build server", false},
+		{"84:38:35:00:00:01", "Apple, Inc.", false},
+		{"52:54:00:12:34:56", "", true},
+		{"not-a-mac", "", true},
+	}
+	for _, tt := range tests {
+		vendor, err := r.Lookup(ctx, tt.mac)
+		if vendor != tt.vendor || (err != nil) != tt.err {
+			t.Errorf("received %q, %v for %s; expected %q", vendor, err, tt.mac, tt.vendor)
+		}
+	}
+
+	if vendor, err := Chain(overrides, Embedded()).Lookup(ctx, "52:54:00:12:34:56"); vendor != "" || err != nil {
+		t.Errorf("expected an unknown vendor without failure, received %q %v", vendor, err)
+	}
+}
+
+func TestCached(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	counting := resolverFunc(func(ctx context.Context, mac string) (string, error) {
+		calls++
+		return Embedded().Lookup(ctx, mac)
+	})
+
+	r := Cached(counting, 2, 0)
+	for _, mac := range []string{"84:38:35:77:aa:52", "84-38-35-77-AA-52", "52:54:00:12:34:56", "52:54:00:12:34:56"} {
+		r.Lookup(ctx, mac)
+	}
+	if calls != 2 {
+		t.Errorf("resolved %d times; expected hits and misses to be cached", calls)
+	}
+
+	calls = 0
+	r = Cached(counting, 2, time.Nanosecond)
+	r.Lookup(ctx, "84:38:35:77:aa:52")
+	time.Sleep(time.Millisecond)
+	if vendor, _ := r.Lookup(ctx, "84:38:35:77:aa:52"); vendor != "Apple, Inc." || calls != 2 {
+		t.Errorf("expected an expired entry to be resolved again, received %q after %d calls", vendor, calls)
+	}
+}
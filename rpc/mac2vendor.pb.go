@@ -0,0 +1,793 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: mac2vendor.proto
+
+package rpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type LookupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mac           string                 `protobuf:"bytes,1,opt,name=mac,proto3" json:"mac,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupRequest) Reset() {
+	*x = LookupRequest{}
+	mi := &file_mac2vendor_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupRequest) ProtoMessage() {}
+
+func (x *LookupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mac2vendor_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupRequest.ProtoReflect.Descriptor instead.
+func (*LookupRequest) Descriptor() ([]byte, []int) {
+	return file_mac2vendor_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LookupRequest) GetMac() string {
+	if x != nil {
+		return x.Mac
+	}
+	return ""
+}
+
+type LookupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       *Address               `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupResponse) Reset() {
+	*x = LookupResponse{}
+	mi := &file_mac2vendor_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupResponse) ProtoMessage() {}
+
+func (x *LookupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mac2vendor_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupResponse.ProtoReflect.Descriptor instead.
+func (*LookupResponse) Descriptor() ([]byte, []int) {
+	return file_mac2vendor_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LookupResponse) GetAddress() *Address {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+type BatchLookupResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// index is the zero based position of the request in the stream
+	Index uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Input string `protobuf:"bytes,2,opt,name=input,proto3" json:"input,omitempty"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*BatchLookupResponse_Address
+	//	*BatchLookupResponse_Error
+	Result        isBatchLookupResponse_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchLookupResponse) Reset() {
+	*x = BatchLookupResponse{}
+	mi := &file_mac2vendor_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchLookupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchLookupResponse) ProtoMessage() {}
+
+func (x *BatchLookupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mac2vendor_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchLookupResponse.ProtoReflect.Descriptor instead.
+func (*BatchLookupResponse) Descriptor() ([]byte, []int) {
+	return file_mac2vendor_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchLookupResponse) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BatchLookupResponse) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+func (x *BatchLookupResponse) GetResult() isBatchLookupResponse_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *BatchLookupResponse) GetAddress() *Address {
+	if x != nil {
+		if x, ok := x.Result.(*BatchLookupResponse_Address); ok {
+			return x.Address
+		}
+	}
+	return nil
+}
+
+func (x *BatchLookupResponse) GetError() *Error {
+	if x != nil {
+		if x, ok := x.Result.(*BatchLookupResponse_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+type isBatchLookupResponse_Result interface {
+	isBatchLookupResponse_Result()
+}
+
+type BatchLookupResponse_Address struct {
+	Address *Address `protobuf:"bytes,3,opt,name=address,proto3,oneof"`
+}
+
+type BatchLookupResponse_Error struct {
+	Error *Error `protobuf:"bytes,4,opt,name=error,proto3,oneof"`
+}
+
+func (*BatchLookupResponse_Address) isBatchLookupResponse_Result() {}
+
+func (*BatchLookupResponse_Error) isBatchLookupResponse_Result() {}
+
+type SearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// query is matched against vendor names, ignoring case, and prefixes
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// limit bounds the number of vendors returned, unlimited when zero
+	Limit         uint32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_mac2vendor_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mac2vendor_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_mac2vendor_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vendors       []*Vendor              `protobuf:"bytes,1,rep,name=vendors,proto3" json:"vendors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_mac2vendor_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mac2vendor_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_mac2vendor_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SearchResponse) GetVendors() []*Vendor {
+	if x != nil {
+		return x.Vendors
+	}
+	return nil
+}
+
+type InfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InfoRequest) Reset() {
+	*x = InfoRequest{}
+	mi := &file_mac2vendor_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InfoRequest) ProtoMessage() {}
+
+func (x *InfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mac2vendor_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InfoRequest.ProtoReflect.Descriptor instead.
+func (*InfoRequest) Descriptor() ([]byte, []int) {
+	return file_mac2vendor_proto_rawDescGZIP(), []int{5}
+}
+
+type InfoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       uint64                 `protobuf:"varint,1,opt,name=entries,proto3" json:"entries,omitempty"`
+	Updated       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=updated,proto3" json:"updated,omitempty"`
+	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InfoResponse) Reset() {
+	*x = InfoResponse{}
+	mi := &file_mac2vendor_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InfoResponse) ProtoMessage() {}
+
+func (x *InfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mac2vendor_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InfoResponse.ProtoReflect.Descriptor instead.
+func (*InfoResponse) Descriptor() ([]byte, []int) {
+	return file_mac2vendor_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *InfoResponse) GetEntries() uint64 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *InfoResponse) GetUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Updated
+	}
+	return nil
+}
+
+func (x *InfoResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type Address struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Mac        string                 `protobuf:"bytes,1,opt,name=mac,proto3" json:"mac,omitempty"`
+	Normalized string                 `protobuf:"bytes,2,opt,name=normalized,proto3" json:"normalized,omitempty"`
+	Prefix     string                 `protobuf:"bytes,3,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// vendor is unset when the prefix is not registered
+	Vendor        *Vendor `protobuf:"bytes,4,opt,name=vendor,proto3" json:"vendor,omitempty"`
+	Flags         *Flags  `protobuf:"bytes,5,opt,name=flags,proto3" json:"flags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Address) Reset() {
+	*x = Address{}
+	mi := &file_mac2vendor_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Address) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Address) ProtoMessage() {}
+
+func (x *Address) ProtoReflect() protoreflect.Message {
+	mi := &file_mac2vendor_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Address.ProtoReflect.Descriptor instead.
+func (*Address) Descriptor() ([]byte, []int) {
+	return file_mac2vendor_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Address) GetMac() string {
+	if x != nil {
+		return x.Mac
+	}
+	return ""
+}
+
+func (x *Address) GetNormalized() string {
+	if x != nil {
+		return x.Normalized
+	}
+	return ""
+}
+
+func (x *Address) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *Address) GetVendor() *Vendor {
+	if x != nil {
+		return x.Vendor
+	}
+	return nil
+}
+
+func (x *Address) GetFlags() *Flags {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+type Vendor struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Prefix        string                 `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Vendor) Reset() {
+	*x = Vendor{}
+	mi := &file_mac2vendor_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Vendor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vendor) ProtoMessage() {}
+
+func (x *Vendor) ProtoReflect() protoreflect.Message {
+	mi := &file_mac2vendor_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vendor.ProtoReflect.Descriptor instead.
+func (*Vendor) Descriptor() ([]byte, []int) {
+	return file_mac2vendor_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Vendor) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Vendor) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type Flags struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Multicast     bool                   `protobuf:"varint,1,opt,name=multicast,proto3" json:"multicast,omitempty"`
+	Broadcast     bool                   `protobuf:"varint,2,opt,name=broadcast,proto3" json:"broadcast,omitempty"`
+	Local         bool                   `protobuf:"varint,3,opt,name=local,proto3" json:"local,omitempty"`
+	Virtual       bool                   `protobuf:"varint,4,opt,name=virtual,proto3" json:"virtual,omitempty"`
+	Hypervisor    string                 `protobuf:"bytes,5,opt,name=hypervisor,proto3" json:"hypervisor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Flags) Reset() {
+	*x = Flags{}
+	mi := &file_mac2vendor_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Flags) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Flags) ProtoMessage() {}
+
+func (x *Flags) ProtoReflect() protoreflect.Message {
+	mi := &file_mac2vendor_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Flags.ProtoReflect.Descriptor instead.
+func (*Flags) Descriptor() ([]byte, []int) {
+	return file_mac2vendor_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Flags) GetMulticast() bool {
+	if x != nil {
+		return x.Multicast
+	}
+	return false
+}
+
+func (x *Flags) GetBroadcast() bool {
+	if x != nil {
+		return x.Broadcast
+	}
+	return false
+}
+
+func (x *Flags) GetLocal() bool {
+	if x != nil {
+		return x.Local
+	}
+	return false
+}
+
+func (x *Flags) GetVirtual() bool {
+	if x != nil {
+		return x.Virtual
+	}
+	return false
+}
+
+func (x *Flags) GetHypervisor() string {
+	if x != nil {
+		return x.Hypervisor
+	}
+	return ""
+}
+
+type Error struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Detail        string                 `protobuf:"bytes,2,opt,name=detail,proto3" json:"detail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_mac2vendor_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_mac2vendor_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_mac2vendor_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+var File_mac2vendor_proto protoreflect.FileDescriptor
+
+const file_mac2vendor_proto_rawDesc = "" +
+	"\n" +
+	"\x10mac2vendor.proto\x12\rmac2vendor.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"!\n" +
+	"\rLookupRequest\x12\x10\n" +
+	"\x03mac\x18\x01 \x01(\tR\x03mac\"B\n" +
+	"\x0eLookupResponse\x120\n" +
+	"\aaddress\x18\x01 \x01(\v2\x16.mac2vendor.v1.AddressR\aaddress\"\xad\x01\n" +
+	"\x13BatchLookupResponse\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x04R\x05index\x12\x14\n" +
+	"\x05input\x18\x02 \x01(\tR\x05input\x122\n" +
+	"\aaddress\x18\x03 \x01(\v2\x16.mac2vendor.v1.AddressH\x00R\aaddress\x12,\n" +
+	"\x05error\x18\x04 \x01(\v2\x14.mac2vendor.v1.ErrorH\x00R\x05errorB\b\n" +
+	"\x06result\";\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\rR\x05limit\"A\n" +
+	"\x0eSearchResponse\x12/\n" +
+	"\avendors\x18\x01 \x03(\v2\x15.mac2vendor.v1.VendorR\avendors\"\r\n" +
+	"\vInfoRequest\"x\n" +
+	"\fInfoResponse\x12\x18\n" +
+	"\aentries\x18\x01 \x01(\x04R\aentries\x124\n" +
+	"\aupdated\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\aupdated\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\"\xae\x01\n" +
+	"\aAddress\x12\x10\n" +
+	"\x03mac\x18\x01 \x01(\tR\x03mac\x12\x1e\n" +
+	"\n" +
+	"normalized\x18\x02 \x01(\tR\n" +
+	"normalized\x12\x16\n" +
+	"\x06prefix\x18\x03 \x01(\tR\x06prefix\x12-\n" +
+	"\x06vendor\x18\x04 \x01(\v2\x15.mac2vendor.v1.VendorR\x06vendor\x12*\n" +
+	"\x05flags\x18\x05 \x01(\v2\x14.mac2vendor.v1.FlagsR\x05flags\"4\n" +
+	"\x06Vendor\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06prefix\x18\x02 \x01(\tR\x06prefix\"\x93\x01\n" +
+	"\x05Flags\x12\x1c\n" +
+	"\tmulticast\x18\x01 \x01(\bR\tmulticast\x12\x1c\n" +
+	"\tbroadcast\x18\x02 \x01(\bR\tbroadcast\x12\x14\n" +
+	"\x05local\x18\x03 \x01(\bR\x05local\x12\x18\n" +
+	"\avirtual\x18\x04 \x01(\bR\avirtual\x12\x1e\n" +
+	"\n" +
+	"hypervisor\x18\x05 \x01(\tR\n" +
+	"hypervisor\"3\n" +
+	"\x05Error\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x16\n" +
+	"\x06detail\x18\x02 \x01(\tR\x06detail2\xb0\x02\n" +
+	"\n" +
+	"Mac2Vendor\x12E\n" +
+	"\x06Lookup\x12\x1c.mac2vendor.v1.LookupRequest\x1a\x1d.mac2vendor.v1.LookupResponse\x12S\n" +
+	"\vBatchLookup\x12\x1c.mac2vendor.v1.LookupRequest\x1a\".mac2vendor.v1.BatchLookupResponse(\x010\x01\x12E\n" +
+	"\x06Search\x12\x1c.mac2vendor.v1.SearchRequest\x1a\x1d.mac2vendor.v1.SearchResponse\x12?\n" +
+	"\x04Info\x12\x1a.mac2vendor.v1.InfoRequest\x1a\x1b.mac2vendor.v1.InfoResponseB)Z'github.com/n3integration/mac2vendor/rpcb\x06proto3"
+
+var (
+	file_mac2vendor_proto_rawDescOnce sync.Once
+	file_mac2vendor_proto_rawDescData []byte
+)
+
+func file_mac2vendor_proto_rawDescGZIP() []byte {
+	file_mac2vendor_proto_rawDescOnce.Do(func() {
+		file_mac2vendor_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_mac2vendor_proto_rawDesc), len(file_mac2vendor_proto_rawDesc)))
+	})
+	return file_mac2vendor_proto_rawDescData
+}
+
+var file_mac2vendor_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_mac2vendor_proto_goTypes = []any{
+	(*LookupRequest)(nil),         // 0: mac2vendor.v1.LookupRequest
+	(*LookupResponse)(nil),        // 1: mac2vendor.v1.LookupResponse
+	(*BatchLookupResponse)(nil),   // 2: mac2vendor.v1.BatchLookupResponse
+	(*SearchRequest)(nil),         // 3: mac2vendor.v1.SearchRequest
+	(*SearchResponse)(nil),        // 4: mac2vendor.v1.SearchResponse
+	(*InfoRequest)(nil),           // 5: mac2vendor.v1.InfoRequest
+	(*InfoResponse)(nil),          // 6: mac2vendor.v1.InfoResponse
+	(*Address)(nil),               // 7: mac2vendor.v1.Address
+	(*Vendor)(nil),                // 8: mac2vendor.v1.Vendor
+	(*Flags)(nil),                 // 9: mac2vendor.v1.Flags
+	(*Error)(nil),                 // 10: mac2vendor.v1.Error
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+}
+var file_mac2vendor_proto_depIdxs = []int32{
+	7,  // 0: mac2vendor.v1.LookupResponse.address:type_name -> mac2vendor.v1.Address
+	7,  // 1: mac2vendor.v1.BatchLookupResponse.address:type_name -> mac2vendor.v1.Address
+	10, // 2: mac2vendor.v1.BatchLookupResponse.error:type_name -> mac2vendor.v1.Error
+	8,  // 3: mac2vendor.v1.SearchResponse.vendors:type_name -> mac2vendor.v1.Vendor
+	11, // 4: mac2vendor.v1.InfoResponse.updated:type_name -> google.protobuf.Timestamp
+	8,  // 5: mac2vendor.v1.Address.vendor:type_name -> mac2vendor.v1.Vendor
+	9,  // 6: mac2vendor.v1.Address.flags:type_name -> mac2vendor.v1.Flags
+	0,  // 7: mac2vendor.v1.Mac2Vendor.Lookup:input_type -> mac2vendor.v1.LookupRequest
+	0,  // 8: mac2vendor.v1.Mac2Vendor.BatchLookup:input_type -> mac2vendor.v1.LookupRequest
+	3,  // 9: mac2vendor.v1.Mac2Vendor.Search:input_type -> mac2vendor.v1.SearchRequest
+	5,  // 10: mac2vendor.v1.Mac2Vendor.Info:input_type -> mac2vendor.v1.InfoRequest
+	1,  // 11: mac2vendor.v1.Mac2Vendor.Lookup:output_type -> mac2vendor.v1.LookupResponse
+	2,  // 12: mac2vendor.v1.Mac2Vendor.BatchLookup:output_type -> mac2vendor.v1.BatchLookupResponse
+	4,  // 13: mac2vendor.v1.Mac2Vendor.Search:output_type -> mac2vendor.v1.SearchResponse
+	6,  // 14: mac2vendor.v1.Mac2Vendor.Info:output_type -> mac2vendor.v1.InfoResponse
+	11, // [11:15] is the sub-list for method output_type
+	7,  // [7:11] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_mac2vendor_proto_init() }
+func file_mac2vendor_proto_init() {
+	if File_mac2vendor_proto != nil {
+		return
+	}
+	file_mac2vendor_proto_msgTypes[2].OneofWrappers = []any{
+		(*BatchLookupResponse_Address)(nil),
+		(*BatchLookupResponse_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_mac2vendor_proto_rawDesc), len(file_mac2vendor_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_mac2vendor_proto_goTypes,
+		DependencyIndexes: file_mac2vendor_proto_depIdxs,
+		MessageInfos:      file_mac2vendor_proto_msgTypes,
+	}.Build()
+	File_mac2vendor_proto = out.File
+	file_mac2vendor_proto_goTypes = nil
+	file_mac2vendor_proto_depIdxs = nil
+}
@@ -0,0 +1,244 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: mac2vendor.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Mac2Vendor_Lookup_FullMethodName      = "/mac2vendor.v1.Mac2Vendor/Lookup"
+	Mac2Vendor_BatchLookup_FullMethodName = "/mac2vendor.v1.Mac2Vendor/BatchLookup"
+	Mac2Vendor_Search_FullMethodName      = "/mac2vendor.v1.Mac2Vendor/Search"
+	Mac2Vendor_Info_FullMethodName        = "/mac2vendor.v1.Mac2Vendor/Info"
+)
+
+// Mac2VendorClient is the client API for Mac2Vendor service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Mac2Vendor resolves mac addresses to the vendors registered for their prefix
+type Mac2VendorClient interface {
+	// Lookup resolves a single mac address
+	Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error)
+	// BatchLookup resolves a stream of mac addresses, responding to each in
+	// the order it was received
+	BatchLookup(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[LookupRequest, BatchLookupResponse], error)
+	// Search finds the vendor prefixes matching a name or prefix
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// Info describes the vendor database
+	Info(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*InfoResponse, error)
+}
+
+type mac2VendorClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMac2VendorClient(cc grpc.ClientConnInterface) Mac2VendorClient {
+	return &mac2VendorClient{cc}
+}
+
+func (c *mac2VendorClient) Lookup(ctx context.Context, in *LookupRequest, opts ...grpc.CallOption) (*LookupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupResponse)
+	err := c.cc.Invoke(ctx, Mac2Vendor_Lookup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mac2VendorClient) BatchLookup(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[LookupRequest, BatchLookupResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Mac2Vendor_ServiceDesc.Streams[0], Mac2Vendor_BatchLookup_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LookupRequest, BatchLookupResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Mac2Vendor_BatchLookupClient = grpc.BidiStreamingClient[LookupRequest, BatchLookupResponse]
+
+func (c *mac2VendorClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, Mac2Vendor_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mac2VendorClient) Info(ctx context.Context, in *InfoRequest, opts ...grpc.CallOption) (*InfoResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InfoResponse)
+	err := c.cc.Invoke(ctx, Mac2Vendor_Info_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Mac2VendorServer is the server API for Mac2Vendor service.
+// All implementations must embed UnimplementedMac2VendorServer
+// for forward compatibility.
+//
+// Mac2Vendor resolves mac addresses to the vendors registered for their prefix
+type Mac2VendorServer interface {
+	// Lookup resolves a single mac address
+	Lookup(context.Context, *LookupRequest) (*LookupResponse, error)
+	// BatchLookup resolves a stream of mac addresses, responding to each in
+	// the order it was received
+	BatchLookup(grpc.BidiStreamingServer[LookupRequest, BatchLookupResponse]) error
+	// Search finds the vendor prefixes matching a name or prefix
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// Info describes the vendor database
+	Info(context.Context, *InfoRequest) (*InfoResponse, error)
+	mustEmbedUnimplementedMac2VendorServer()
+}
+
+// UnimplementedMac2VendorServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMac2VendorServer struct{}
+
+func (UnimplementedMac2VendorServer) Lookup(context.Context, *LookupRequest) (*LookupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Lookup not implemented")
+}
+func (UnimplementedMac2VendorServer) BatchLookup(grpc.BidiStreamingServer[LookupRequest, BatchLookupResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method BatchLookup not implemented")
+}
+func (UnimplementedMac2VendorServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedMac2VendorServer) Info(context.Context, *InfoRequest) (*InfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Info not implemented")
+}
+func (UnimplementedMac2VendorServer) mustEmbedUnimplementedMac2VendorServer() {}
+func (UnimplementedMac2VendorServer) testEmbeddedByValue()                    {}
+
+// UnsafeMac2VendorServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to Mac2VendorServer will
+// result in compilation errors.
+type UnsafeMac2VendorServer interface {
+	mustEmbedUnimplementedMac2VendorServer()
+}
+
+func RegisterMac2VendorServer(s grpc.ServiceRegistrar, srv Mac2VendorServer) {
+	// If the following call pancis, it indicates UnimplementedMac2VendorServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Mac2Vendor_ServiceDesc, srv)
+}
+
+func _Mac2Vendor_Lookup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Mac2VendorServer).Lookup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Mac2Vendor_Lookup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Mac2VendorServer).Lookup(ctx, req.(*LookupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Mac2Vendor_BatchLookup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(Mac2VendorServer).BatchLookup(&grpc.GenericServerStream[LookupRequest, BatchLookupResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Mac2Vendor_BatchLookupServer = grpc.BidiStreamingServer[LookupRequest, BatchLookupResponse]
+
+func _Mac2Vendor_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Mac2VendorServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Mac2Vendor_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Mac2VendorServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Mac2Vendor_Info_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Mac2VendorServer).Info(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Mac2Vendor_Info_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Mac2VendorServer).Info(ctx, req.(*InfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Mac2Vendor_ServiceDesc is the grpc.ServiceDesc for Mac2Vendor service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Mac2Vendor_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mac2vendor.v1.Mac2Vendor",
+	HandlerType: (*Mac2VendorServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Lookup",
+			Handler:    _Mac2Vendor_Lookup_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _Mac2Vendor_Search_Handler,
+		},
+		{
+			MethodName: "Info",
+			Handler:    _Mac2Vendor_Info_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchLookup",
+			Handler:       _Mac2Vendor_BatchLookup_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "mac2vendor.proto",
+}
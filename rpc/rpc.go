@@ -0,0 +1,5 @@
+// Package rpc provides the protobuf messages and grpc service definition of
+// the mac2vendor service
+package rpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative mac2vendor.proto
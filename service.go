@@ -0,0 +1,78 @@
+package mac2vendor
+
+import (
+	"context"
+	"time"
+)
+
+// Service resolves mac addresses and describes the vendor database. It is
+// implemented by the in-process database and by remote clients, so that
+// callers may swap local and remote resolution.
+type Service interface {
+	Resolver
+	// LookupBatch resolves each address, returning the results in order
+	LookupBatch(ctx context.Context, macs []string) ([]Result, error)
+	// Search returns up to limit entries matching the query, without limit
+	// when it is zero
+	Search(ctx context.Context, query string, limit int) ([]Entry, error)
+	// Info describes the vendor database
+	Info(ctx context.Context) (*Info, error)
+}
+
+// Result is the outcome of resolving a single address of a batch
+type Result struct {
+	MAC    string
+	Vendor string
+	Err    error
+}
+
+// Info describes a vendor database
+type Info struct {
+	Entries int
+	Updated time.Time
+	Version string
+}
+
+type local struct{}
+
+// Local returns the service backed by the in-process database
+func Local() Service {
+	return local{}
+}
+
+func (local) Lookup(ctx context.Context, mac string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return Lookup(mac)
+}
+
+func (local) LookupBatch(ctx context.Context, macs []string) ([]Result, error) {
+	results := make([]Result, 0, len(macs))
+	for _, mac := range macs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		vendor, err := Lookup(mac)
+		results = append(results, Result{MAC: mac, Vendor: vendor, Err: err})
+	}
+	return results, nil
+}
+
+func (local) Search(ctx context.Context, query string, limit int) ([]Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	entries := Search(query)
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+func (local) Info(ctx context.Context) (*Info, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &Info{Entries: Len(), Updated: Updated(), Version: Version()}, nil
+}
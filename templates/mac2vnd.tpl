@@ -1,7 +1,10 @@
 package mac2vendor
 
+import "time"
+
 func init() {
-    {{- range $key, $value := . }}
+    updated = time.Unix({{ .Updated }}, 0).UTC()
+    {{- range $key, $value := .Mapping }}
     mapping["{{ $key }}"] = "{{ $value }}"
     {{- end }}
 }
\ No newline at end of file
@@ -0,0 +1,43 @@
+package mac2vendor
+
+import (
+	"strings"
+)
+
+// hypervisors lists the address prefixes assigned to virtual network
+// interfaces by well known virtualization platforms
+var hypervisors = []struct {
+	prefix   string
+	platform string
+}{
+	{"00:05:69", "VMware"},
+	{"00:0c:29", "VMware"},
+	{"00:1c:14", "VMware"},
+	{"00:50:56", "VMware"},
+	{"08:00:27", "VirtualBox"},
+	{"0a:00:27", "VirtualBox"},
+	{"52:54:00", "QEMU/KVM"},
+	{"00:16:3e", "Xen"},
+	{"00:15:5d", "Hyper-V"},
+	{"00:03:ff", "Hyper-V"},
+	{"00:1c:42", "Parallels"},
+	{"02:42", "Docker"},
+}
+
+// Hypervisor resolves the provided MAC address to the virtualization platform
+// that assigned it, or an empty string when the address is not known to be
+// virtual
+func Hypervisor(v interface{}) (string, error) {
+	mac, err := parse(v)
+	if err != nil {
+		return "", err
+	}
+
+	addr := strings.ToLower(mac.String())
+	for _, h := range hypervisors {
+		if strings.HasPrefix(addr, h.prefix) {
+			return h.platform, nil
+		}
+	}
+	return "", nil
+}